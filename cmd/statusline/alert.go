@@ -0,0 +1,296 @@
+// Package main provides the alert subsystem: rules over the input metrics,
+// raised on crossing edges and delivered through configurable channels
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+// Alert rule metrics
+const (
+	metricContext   = "context"    // Context window percent used, fires at or above
+	metricCost      = "cost"       // Session cost in USD, fires at or above
+	metricRateLimit = "rate_limit" // Rate limit percent remaining, fires at or below
+	metricIdle      = "idle"       // Seconds between calls, fires at or above
+)
+
+// Alert levels, in increasing severity
+const (
+	levelWarning = "warning"
+	levelError   = "error"
+)
+
+// Alert delivery channels
+const (
+	channelFlash  = "flash"  // Flash the sprite for flash_seconds
+	channelBell   = "bell"   // Terminal bell
+	channelOSC9   = "osc9"   // OSC 9 desktop notification (iTerm2, Windows Terminal)
+	channelOSC777 = "osc777" // OSC 777 desktop notification (rxvt, foot, Ghostty)
+)
+
+// Flash timing and color
+const (
+	flashPeriod = 500 * time.Millisecond // Time per flash phase
+	flashColor  = 226                    // Yellow
+)
+
+// notifyTitle is the title of OSC 777 notifications
+const notifyTitle = "Claude Ride"
+
+// alertRule raises an alert when a metric crosses its threshold
+// Rules sharing a name are alternatives: only the most severe firing one
+// is active, so a context rule can escalate from warning to error
+type alertRule struct {
+	Name      string  `json:"name"`
+	Metric    string  `json:"metric"`    // context, cost, rate_limit or idle
+	Threshold float64 `json:"threshold"` // Percent, USD or seconds depending on the metric
+	Level     string  `json:"level"`     // warning or error
+}
+
+// alertsConfig is the alert rules and where raised alerts go
+type alertsConfig struct {
+	Rules        []alertRule `json:"rules"`
+	Channels     []string    `json:"channels"`      // flash, bell, osc9 and osc777
+	FlashSeconds int         `json:"flash_seconds"` // How long the sprite flashes
+}
+
+// defaultAlertRules returns the built-in context and rate limit alerts
+func defaultAlertRules() []alertRule {
+	return []alertRule{
+		{Name: "context", Metric: metricContext, Threshold: contextWarningRatio * 100, Level: levelWarning},
+		{Name: "context", Metric: metricContext, Threshold: contextErrorRatio * 100, Level: levelError},
+		{Name: "rate_limit", Metric: metricRateLimit, Threshold: rateLimitLowRatio * 100, Level: levelWarning},
+	}
+}
+
+// defaultAlertsConfig returns the alert defaults: built-in rules, flash only
+func defaultAlertsConfig() alertsConfig {
+	return alertsConfig{
+		Rules:        defaultAlertRules(),
+		Channels:     []string{channelFlash},
+		FlashSeconds: 5,
+	}
+}
+
+// UnmarshalJSON decodes over the current value like encoding/json, except
+// that a rules list replaces the default rules whole. Decoding into the
+// default slice would let a user rule inherit every field it leaves out
+func (c *alertsConfig) UnmarshalJSON(data []byte) error {
+	type plain alertsConfig // Same fields without this method
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["rules"]; ok {
+		c.Rules = nil
+	}
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// validate checks rule metrics, levels and channel names
+func (c alertsConfig) validate() error {
+	for i, r := range c.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("alert rule %d: name is required", i)
+		}
+		switch r.Metric {
+		case metricContext, metricCost, metricRateLimit, metricIdle:
+		default:
+			return fmt.Errorf("alert rule %q: invalid metric %q: want context, cost, rate_limit or idle", r.Name, r.Metric)
+		}
+		if levelRank(r.Level) == 0 {
+			return fmt.Errorf("alert rule %q: invalid level %q: want warning or error", r.Name, r.Level)
+		}
+		if r.Threshold < 0 {
+			return fmt.Errorf("alert rule %q: threshold must not be negative", r.Name)
+		}
+	}
+	for _, ch := range c.Channels {
+		switch ch {
+		case channelFlash, channelBell, channelOSC9, channelOSC777:
+		default:
+			return fmt.Errorf("invalid alert channel %q: want flash, bell, osc9 or osc777", ch)
+		}
+	}
+	if c.FlashSeconds < 0 {
+		return fmt.Errorf("alerts flash_seconds must not be negative")
+	}
+	return nil
+}
+
+// flashDuration returns how long the sprite flashes, 0 when disabled
+func (c alertsConfig) flashDuration() time.Duration {
+	if !slices.Contains(c.Channels, channelFlash) {
+		return 0
+	}
+	return time.Duration(c.FlashSeconds) * time.Second
+}
+
+// levelRank orders alert levels, 0 for unknown ones
+func levelRank(level string) int {
+	switch level {
+	case levelWarning:
+		return 1
+	case levelError:
+		return 2
+	}
+	return 0
+}
+
+// alertMetrics are the values alert rules are evaluated against
+type alertMetrics struct {
+	Context contextInfo
+	Rate    rateInfo
+	CostUSD float64
+	Idle    time.Duration // Time since the previous call, 0 when unknown
+}
+
+// newAlertMetrics returns the metrics of a payload and call gap
+func newAlertMetrics(input *StatusLineInput, idle time.Duration) alertMetrics {
+	m := alertMetrics{Context: contextUsage(input), Rate: rateLimitUsage(input), Idle: idle}
+	if input != nil {
+		m.CostUSD = input.Cost.TotalCostUSD
+	}
+	return m
+}
+
+// firing reports whether the rule's metric crossed its threshold
+func (r alertRule) firing(m alertMetrics) bool {
+	switch r.Metric {
+	case metricContext:
+		return m.Context.WindowSize > 0 && m.Context.Ratio >= r.Threshold/100
+	case metricCost:
+		return m.CostUSD > 0 && m.CostUSD >= r.Threshold
+	case metricRateLimit:
+		return m.Rate.Limit > 0 && m.Rate.Ratio <= r.Threshold/100
+	case metricIdle:
+		return m.Idle > 0 && m.Idle.Seconds() >= r.Threshold
+	}
+	return false
+}
+
+// message describes the metric that fired the rule
+func (r alertRule) message(m alertMetrics) string {
+	switch r.Metric {
+	case metricContext:
+		return fmt.Sprintf("context window %.0f%% full", m.Context.Ratio*100)
+	case metricCost:
+		return fmt.Sprintf("session cost $%.2f", m.CostUSD)
+	case metricRateLimit:
+		return fmt.Sprintf("rate limit low: %d/%d remaining", m.Rate.Remaining, m.Rate.Limit)
+	case metricIdle:
+		return "idle for " + formatDuration(m.Idle)
+	}
+	return r.Name
+}
+
+// evaluateAlerts returns the active alerts, one per rule name in the order
+// the names first appear, each at its most severe firing level
+func evaluateAlerts(rules []alertRule, m alertMetrics) []alertEvent {
+	alerts := []alertEvent{}
+	index := map[string]int{}
+	for _, r := range rules {
+		if !r.firing(m) {
+			continue
+		}
+		event := alertEvent{Name: r.Name, Level: r.Level, Message: r.message(m)}
+		i, seen := index[r.Name]
+		switch {
+		case !seen:
+			index[r.Name] = len(alerts)
+			alerts = append(alerts, event)
+		case levelRank(r.Level) > levelRank(alerts[i].Level):
+			alerts[i] = event
+		}
+	}
+	return alerts
+}
+
+// raiseAlerts evaluates the rules for a payload arriving at now and
+// records which alerts are new since the last call. An alert is raised
+// when its rule starts firing or escalates; a standing alert is never
+// raised again, however often the statusline renders. It runs before the
+// call time is recorded, so the idle metric sees the previous call
+func raiseAlerts(st *sessionState, input *StatusLineInput, now time.Time, rules []alertRule) {
+	var idle time.Duration
+	if !st.LastCall.IsZero() {
+		idle = now.Sub(st.LastCall)
+	}
+	active := evaluateAlerts(rules, newAlertMetrics(input, idle))
+
+	st.Raised = nil
+	firing := make(map[string]string, len(active))
+	for _, a := range active {
+		firing[a.Name] = a.Level
+		if levelRank(a.Level) > levelRank(st.Firing[a.Name]) {
+			st.Raised = append(st.Raised, a)
+		}
+	}
+	st.Firing = firing
+	if len(st.Raised) > 0 {
+		st.RaisedAt = now
+	}
+}
+
+// raisedError reports whether an error level alert was raised by the
+// latest call
+func (s *sessionState) raisedError() bool {
+	return slices.ContainsFunc(s.Raised, func(a alertEvent) bool { return a.Level == levelError })
+}
+
+// flashing reports whether the sprite is in the bright phase of an alert
+// flash at now
+func (s *sessionState) flashing(now time.Time, d time.Duration) bool {
+	if s == nil || s.RaisedAt.IsZero() || d <= 0 {
+		return false
+	}
+	since := now.Sub(s.RaisedAt)
+	return since >= 0 && since < d && (since/flashPeriod)%2 == 0
+}
+
+// writeAlertSequences writes the bell and notification sequences of the
+// enabled channels for each raised alert
+func writeAlertSequences(w io.Writer, channels []string, alerts []alertEvent) {
+	for _, a := range alerts {
+		message := sanitizeText(a.Name + ": " + a.Message)
+		for _, ch := range channels {
+			switch ch {
+			case channelBell:
+				io.WriteString(w, "\a")
+			case channelOSC9:
+				fmt.Fprintf(w, "\x1b]9;%s\a", message)
+			case channelOSC777:
+				fmt.Fprintf(w, "\x1b]777;notify;%s;%s\a", notifyTitle, message)
+			}
+		}
+	}
+}
+
+// notifyAlerts delivers raised alerts to the terminal
+func notifyAlerts(cfg alertsConfig, alerts []alertEvent, debugFile *os.File) {
+	var buf bytes.Buffer
+	writeAlertSequences(&buf, cfg.Channels, alerts)
+	writeTTY(buf.String(), debugFile)
+}
+
+// writeTTY writes control sequences straight to the terminal
+// Claude Code captures stdout, so sequences meant for the terminal itself
+// cannot go through the statusline output
+func writeTTY(seq string, debugFile *os.File) {
+	if seq == "" {
+		return
+	}
+	tty, err := openTTY()
+	if err != nil {
+		debugLog(debugFile, "tty: %v", err)
+		return
+	}
+	defer tty.Close()
+	io.WriteString(tty, seq)
+}
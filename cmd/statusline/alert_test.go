@@ -0,0 +1,91 @@
+// Package main provides tests for the alert subsystem
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateAlerts_MostSevereLevelPerName(t *testing.T) {
+	rules := defaultAlertRules()
+	assert.Empty(t, evaluateAlerts(rules, newAlertMetrics(testPayload{Used: 50, Window: 100}.build(), 0)))
+
+	alerts := evaluateAlerts(rules, newAlertMetrics(testPayload{Used: 85, Window: 100}.build(), 0))
+	assert.Equal(t, []alertEvent{{Name: "context", Level: levelWarning, Message: "context window 85% full"}}, alerts)
+
+	alerts = evaluateAlerts(rules, newAlertMetrics(testPayload{Used: 97, Window: 100}.build(), 0))
+	assert.Equal(t, []alertEvent{{Name: "context", Level: levelError, Message: "context window 97% full"}}, alerts)
+}
+
+func TestEvaluateAlerts_CostAndIdle(t *testing.T) {
+	rules := []alertRule{
+		{Name: "budget", Metric: metricCost, Threshold: 5, Level: levelWarning},
+		{Name: "away", Metric: metricIdle, Threshold: 600, Level: levelWarning},
+	}
+	alerts := evaluateAlerts(rules, newAlertMetrics(testPayload{Used: 10, Window: 100, CostUSD: 5.5}.build(), 15*time.Minute))
+	assert.Equal(t, []alertEvent{
+		{Name: "budget", Level: levelWarning, Message: "session cost $5.50"},
+		{Name: "away", Level: levelWarning, Message: "idle for 15m"},
+	}, alerts)
+}
+
+func TestRaiseAlerts_OnlyOnEdges(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	rules := defaultAlertRules()
+	var st sessionState
+
+	updateSessionState(&st, testPayload{Used: 50, Window: 100}.build(), t0, rules)
+	assert.Empty(t, st.Raised)
+
+	updateSessionState(&st, testPayload{Used: 85, Window: 100}.build(), t0.Add(time.Second), rules)
+	assert.Len(t, st.Raised, 1, "crossing the warning threshold raises")
+	assert.Equal(t, t0.Add(time.Second), st.RaisedAt)
+
+	for i := 2; i < 5; i++ {
+		updateSessionState(&st, testPayload{Used: 86, Window: 100}.build(), t0.Add(time.Duration(i)*time.Second), rules)
+		assert.Empty(t, st.Raised, "a standing alert is not raised again")
+	}
+
+	updateSessionState(&st, testPayload{Used: 96, Window: 100}.build(), t0.Add(5*time.Second), rules)
+	if assert.Len(t, st.Raised, 1, "escalation raises") {
+		assert.Equal(t, levelError, st.Raised[0].Level)
+	}
+
+	updateSessionState(&st, testPayload{Used: 90, Window: 100}.build(), t0.Add(6*time.Second), rules)
+	assert.Empty(t, st.Raised, "de-escalation does not raise")
+
+	updateSessionState(&st, testPayload{Used: 30, Window: 100}.build(), t0.Add(7*time.Second), rules)
+	updateSessionState(&st, testPayload{Used: 85, Window: 100}.build(), t0.Add(8*time.Second), rules)
+	assert.Len(t, st.Raised, 1, "a cleared alert raises again")
+}
+
+func TestFlashing(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	st := &sessionState{RaisedAt: t0}
+	assert.True(t, st.flashing(t0, 5*time.Second))
+	assert.False(t, st.flashing(t0.Add(flashPeriod), 5*time.Second), "dark phase")
+	assert.True(t, st.flashing(t0.Add(2*flashPeriod), 5*time.Second))
+	assert.False(t, st.flashing(t0.Add(6*time.Second), 5*time.Second), "over")
+	assert.False(t, st.flashing(t0, 0), "flash channel disabled")
+
+	var none *sessionState
+	assert.False(t, none.flashing(t0, 5*time.Second))
+}
+
+func TestWriteAlertSequences(t *testing.T) {
+	alerts := []alertEvent{{Name: "context", Level: levelError, Message: "context window 96% full\x1b]0;pwned\a"}}
+
+	var out bytes.Buffer
+	writeAlertSequences(&out, []string{channelFlash, channelBell, channelOSC9, channelOSC777}, alerts)
+	assert.Equal(t, "\a"+
+		"\x1b]9;context: context window 96% full"+sanitizeText("\x1b]0;pwned\a")+"\a"+
+		"\x1b]777;notify;Claude Ride;context: context window 96% full"+sanitizeText("\x1b]0;pwned\a")+"\a",
+		out.String())
+
+	out.Reset()
+	writeAlertSequences(&out, []string{channelFlash}, alerts)
+	assert.Empty(t, out.String(), "flash is drawn by the renderer")
+}
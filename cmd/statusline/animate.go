@@ -0,0 +1,131 @@
+// Package main provides the continuous terminal animation mode
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// headerBoxWidth is the terminal cell width of the framed animation header
+const headerBoxWidth = 62
+
+// animationLayout describes how the animation fits into the terminal
+type animationLayout struct {
+	Cols       int  // Terminal width in cells, 0 when unknown
+	Rows       int  // Terminal height in cells, 0 when unknown
+	TrackWidth int  // Width of the dotted track in cells
+	Compact    bool // Terminal is narrower than the horse sprite
+}
+
+// computeLayout derives the animation layout for a terminal size
+// Unknown sizes (0) keep the full 95-cell track
+func computeLayout(cols, rows int) animationLayout {
+	fullWidth := StringWidth(HorseFrames[0][0])
+	layout := animationLayout{
+		Cols:       cols,
+		Rows:       rows,
+		TrackWidth: fullWidth,
+	}
+	if cols <= 0 {
+		return layout
+	}
+	if cols < layout.TrackWidth {
+		layout.TrackWidth = cols
+	}
+	layout.Compact = cols < MaxSpriteWidth()
+	return layout
+}
+
+// animationHeader returns the header lines centered for the layout
+// Terminals narrower than the box get a single plain title line
+func animationHeader(layout animationLayout) []string {
+	if layout.Cols > 0 && layout.Cols < headerBoxWidth {
+		title := TruncateWidth("🐴 Claude Ride With Whip", layout.Cols)
+		return []string{colorRed160 + PadCenter(title, layout.Cols) + colorReset}
+	}
+
+	lines := []string{
+		colorRed160 + "╔════════════════════════════════════════════════════════════╗" + colorReset,
+		colorRed160 + "║" + colorReset + "         🐴 Claude Ride With Whip - Animation Demo 🐴         " + colorRed160 + "║" + colorReset,
+		colorRed160 + "║" + colorReset + "                  Press Ctrl+C to exit                    " + colorRed160 + "║" + colorReset,
+		colorRed160 + "╚════════════════════════════════════════════════════════════╝" + colorReset,
+	}
+	if layout.Cols > headerBoxWidth {
+		pad := strings.Repeat(" ", (layout.Cols-headerBoxWidth)/2)
+		for i := range lines {
+			lines[i] = pad + lines[i]
+		}
+	}
+	return lines
+}
+
+// animationHorse returns the horse lines for the layout at the given time
+// Compact layouts show only the horse head, cut to the terminal width
+func animationHorse(layout animationLayout, now time.Time) []string {
+	if layout.Compact {
+		return []string{TruncateWidth("🐴", layout.Cols)}
+	}
+	return getHorseLinesWidth(nil, now, layout.TrackWidth)
+}
+
+// animationScreen renders one full screen of the animation mode
+func animationScreen(layout animationLayout, now time.Time) string {
+	var screen strings.Builder
+
+	// Clear screen and move cursor to home
+	screen.WriteString(colorClear)
+
+	// Display header
+	for _, line := range animationHeader(layout) {
+		screen.WriteString(line + "\n")
+	}
+	screen.WriteString("\n")
+
+	// Render horse
+	for _, line := range animationHorse(layout, now) {
+		screen.WriteString(colorizeLine(line) + "\n")
+	}
+
+	screen.WriteString("\n")
+	slogan := "✨ 马到成功 · 一马当先 · 龙马精神 ✨"
+	if layout.Cols > 0 {
+		slogan = PadCenter(TruncateWidth(slogan, layout.Cols), layout.Cols)
+	}
+	screen.WriteString(colorRed160 + slogan + colorReset + "\n")
+
+	return screen.String()
+}
+
+// currentLayout measures the terminal and returns its animation layout
+func currentLayout() animationLayout {
+	cols, rows, _ := terminalSize()
+	return computeLayout(cols, rows)
+}
+
+// runAnimationMode runs continuous animation in the terminal
+// The layout is recomputed on SIGWINCH, or polled where signals are unavailable
+func runAnimationMode() {
+	// Clear screen once at start
+	fmt.Print(colorClear)
+
+	resized, stop := notifyResize()
+	defer stop()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	layout := currentLayout()
+	for {
+		select {
+		case <-resized:
+			layout = currentLayout()
+		case <-ticker.C:
+			if resizeNeedsPolling {
+				layout = currentLayout()
+			}
+		}
+
+		fmt.Print(animationScreen(layout, time.Now()))
+	}
+}
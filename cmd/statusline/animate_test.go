@@ -0,0 +1,63 @@
+// Package main provides tests for the terminal animation mode
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeLayout(t *testing.T) {
+	tests := []struct {
+		name          string
+		cols          int
+		expectedTrack int
+		compact       bool
+	}{
+		{name: "unknown size keeps full track", cols: 0, expectedTrack: 95},
+		{name: "wide terminal keeps full track", cols: 200, expectedTrack: 95},
+		{name: "narrow terminal shrinks track", cols: 40, expectedTrack: 40},
+		{name: "narrower than sprite is compact", cols: 5, expectedTrack: 5, compact: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := computeLayout(tt.cols, 24)
+			assert.Equal(t, tt.expectedTrack, layout.TrackWidth)
+			assert.Equal(t, tt.compact, layout.Compact)
+		})
+	}
+}
+
+func TestGetHorseLinesWidth_FitsTrack(t *testing.T) {
+	// Every line must fit the requested track width at every position
+	for _, width := range []int{8, 12, 20, 40, 95} {
+		for ms := int64(0); ms < 60000; ms += 250 {
+			lines := getHorseLinesWidth(nil, time.UnixMilli(ms), width)
+			for i, line := range lines {
+				assert.LessOrEqual(t, StringWidth(line), width,
+					"width %d at %dms: line %d too wide: %q", width, ms, i, line)
+			}
+		}
+	}
+}
+
+func TestAnimationHeader_Centered(t *testing.T) {
+	// Wide terminals center the header box
+	lines := animationHeader(computeLayout(100, 24))
+	assert.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], strings.Repeat(" ", 19)),
+		"header should be padded to the center, got %q", lines[0])
+
+	// Narrow terminals get a single line title
+	lines = animationHeader(computeLayout(30, 24))
+	assert.Len(t, lines, 1)
+}
+
+func TestAnimationScreen_CompactHorse(t *testing.T) {
+	screen := animationScreen(computeLayout(4, 10), time.UnixMilli(0))
+	assert.Contains(t, screen, "🐴")
+	assert.NotContains(t, screen, "....", "compact layout should not draw the track")
+}
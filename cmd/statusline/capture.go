@@ -0,0 +1,153 @@
+// Package main provides recording of raw payloads to a rotating capture file
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// captureFile is the default capture file name in the cache directory
+const captureFile = "captures.jsonl"
+
+// recordConfig controls payload recording
+type recordConfig struct {
+	Enabled  bool   `json:"enabled"`   // Also enabled by --record
+	Path     string `json:"path"`      // Capture file, empty for the cache directory
+	MaxBytes int64  `json:"max_bytes"` // Rotate when the file would grow beyond this
+	Keep     int    `json:"keep"`      // Rotated files kept as path.1 ... path.N
+}
+
+// defaultRecordConfig returns the recording defaults
+func defaultRecordConfig() recordConfig {
+	return recordConfig{MaxBytes: 5 << 20, Keep: 3}
+}
+
+// validate rejects a size limit that would rotate on every write
+func (c recordConfig) validate() error {
+	if c.MaxBytes <= 0 || c.Keep < 0 {
+		return fmt.Errorf("record max_bytes must be positive and keep non-negative")
+	}
+	return nil
+}
+
+// file returns the capture file location
+func (c recordConfig) file() (string, error) {
+	if c.Path != "" {
+		return c.Path, nil
+	}
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, captureFile), nil
+}
+
+// captureRecord is one line of the capture file
+// The payload is kept as a string so invalid JSON is captured verbatim
+type captureRecord struct {
+	Time    time.Time `json:"time"`
+	Payload string    `json:"payload"`
+}
+
+// recordPayload appends the raw payload with its arrival time to the
+// capture file, rotating it first when the line would exceed MaxBytes
+func recordPayload(cfg recordConfig, now time.Time, payload []byte) error {
+	path, err := cfg.file()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(captureRecord{Time: now, Payload: string(payload)})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line)) > cfg.MaxBytes {
+		if err := rotateCaptures(path, cfg.Keep); err != nil {
+			return err
+		}
+	}
+
+	// A single O_APPEND write keeps lines from concurrent invocations whole
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rotateCaptures shifts path to path.1, path.1 to path.2 and so on,
+// dropping the oldest file beyond keep
+func rotateCaptures(path string, keep int) error {
+	if keep == 0 {
+		return ignoreNotExist(os.Remove(path))
+	}
+	if err := ignoreNotExist(os.Remove(fmt.Sprintf("%s.%d", path, keep))); err != nil {
+		return err
+	}
+	for i := keep - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+		if err := ignoreNotExist(err); err != nil {
+			return err
+		}
+	}
+	return ignoreNotExist(os.Rename(path, path+".1"))
+}
+
+// ignoreNotExist treats a missing file as success
+func ignoreNotExist(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// readCaptures reads a capture file, ordered by arrival time
+// A truncated last line, left by an interrupted write, is skipped
+func readCaptures(r io.Reader) ([]captureRecord, error) {
+	var records []captureRecord
+	br := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		complete := bytes.HasSuffix(line, []byte("\n"))
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var rec captureRecord
+			if jsonErr := json.Unmarshal(trimmed, &rec); jsonErr != nil {
+				if complete {
+					return nil, fmt.Errorf("capture line %d: %w", lineNo, jsonErr)
+				}
+			} else {
+				records = append(records, rec)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	// Concurrent invocations may append slightly out of order
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
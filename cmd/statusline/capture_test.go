@@ -0,0 +1,66 @@
+// Package main provides tests for payload capture and rotation
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordPayload_AppendsAndReadsBack(t *testing.T) {
+	cfg := defaultRecordConfig()
+	cfg.Path = filepath.Join(t.TempDir(), "nested", "captures.jsonl")
+
+	t0 := time.UnixMilli(1_700_000_000_000)
+	require.NoError(t, recordPayload(cfg, t0, []byte(`{"cwd":"/a"}`)))
+	require.NoError(t, recordPayload(cfg, t0.Add(time.Second), []byte("not json\x00")))
+
+	f, err := os.Open(cfg.Path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := readCaptures(f)
+	require.NoError(t, err)
+
+	require.Len(t, records, 2)
+	assert.Equal(t, `{"cwd":"/a"}`, records[0].Payload)
+	assert.Equal(t, "not json\x00", records[1].Payload, "payloads are kept verbatim")
+	assert.True(t, records[1].Time.Equal(t0.Add(time.Second)))
+}
+
+func TestRecordPayload_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	cfg := recordConfig{Path: filepath.Join(dir, "c.jsonl"), MaxBytes: 100, Keep: 2}
+
+	payload := []byte(strings.Repeat("x", 40))
+	for i := 0; i < 7; i++ {
+		require.NoError(t, recordPayload(cfg, time.UnixMilli(int64(i)), payload))
+	}
+
+	for _, name := range []string{"c.jsonl", "c.jsonl.1", "c.jsonl.2"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		assert.LessOrEqual(t, info.Size(), cfg.MaxBytes, name)
+	}
+	assert.NoFileExists(t, filepath.Join(dir, "c.jsonl.3"), "only keep rotated files remain")
+}
+
+func TestReadCaptures_TruncatedLastLine(t *testing.T) {
+	data := `{"time":"2026-01-01T00:00:02Z","payload":"b"}
+{"time":"2026-01-01T00:00:01Z","payload":"a"}
+{"time":"2026-01-01T00:00:03Z","pay`
+
+	records, err := readCaptures(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "a", records[0].Payload, "records are ordered by time")
+}
+
+func TestReadCaptures_CorruptLine(t *testing.T) {
+	_, err := readCaptures(strings.NewReader("garbage\n{}\n"))
+	assert.ErrorContains(t, err, "capture line 1")
+}
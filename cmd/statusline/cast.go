@@ -0,0 +1,64 @@
+// Package main provides asciicast v2 recording of the animation mode
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// castHeader is the first line of an asciicast v2 file
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// castOptions controls the recorded animation
+type castOptions struct {
+	Cols     int           // Terminal width in cells
+	FPS      int           // Frames per second
+	Duration time.Duration // Length of the recording
+	Start    time.Time     // Animation clock at the first frame
+}
+
+// exportCast writes the animate-mode output as an asciicast v2 recording
+// Event times are computed from start and fps instead of recorded live,
+// so the animation clock of every frame matches getHorseLines exactly
+func exportCast(w io.Writer, o castOptions) error {
+	if o.Cols <= 0 || o.FPS <= 0 {
+		return fmt.Errorf("width and fps must be positive")
+	}
+
+	layout := computeLayout(o.Cols, 0)
+	times := frameTimes(o.Start, o.FPS, o.Duration)
+
+	header := castHeader{
+		Version:   2,
+		Width:     o.Cols,
+		Height:    strings.Count(animationScreen(layout, o.Start), "\n") + 1,
+		Timestamp: o.Start.Unix(),
+		Title:     "Claude Ride With Whip",
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	}
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+
+	for _, now := range times {
+		// A real terminal translates "\n" to "\r\n" (ONLCR) on output
+		screen := strings.ReplaceAll(animationScreen(layout, now), "\n", "\r\n")
+		offset := now.Sub(o.Start).Seconds()
+		if err := enc.Encode([]any{offset, "o", screen}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
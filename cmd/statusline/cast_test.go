@@ -0,0 +1,63 @@
+// Package main provides tests for asciicast recording
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCast_HeaderAndEvents(t *testing.T) {
+	var buf bytes.Buffer
+	err := exportCast(&buf, castOptions{
+		Cols:     80,
+		FPS:      4,
+		Duration: time.Second,
+		Start:    time.UnixMilli(1000),
+	})
+	require.NoError(t, err)
+
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(nil, 1<<20)
+	require.True(t, scanner.Scan())
+
+	var header castHeader
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &header))
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, 80, header.Width)
+	assert.Equal(t, int64(1), header.Timestamp)
+
+	var offsets []float64
+	for scanner.Scan() {
+		var event []any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.Len(t, event, 3)
+		assert.Equal(t, "o", event[1])
+		assert.NotContains(t, strings.ReplaceAll(event[2].(string), "\r\n", ""), "\n",
+			"newlines should be translated to CRLF")
+		offsets = append(offsets, event[0].(float64))
+	}
+
+	// Timestamps come from the fps clock, not from wall time
+	assert.Equal(t, []float64{0, 0.25, 0.5, 0.75}, offsets)
+}
+
+func TestExportCast_FramesMatchGetHorseLines(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.UnixMilli(5000)
+	require.NoError(t, exportCast(&buf, castOptions{Cols: 95, FPS: 1, Duration: time.Second, Start: start}))
+
+	lines := strings.Split(buf.String(), "\n")
+	var event []any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+
+	for _, horseLine := range getHorseLines(nil, start) {
+		assert.Contains(t, event[2].(string), colorizeLine(horseLine))
+	}
+}
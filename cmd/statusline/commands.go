@@ -0,0 +1,160 @@
+// Package main provides the subcommands of the statusline binary
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// commandNames lists the subcommands runCommand dispatches
+var commandNames = []string{"export", "tmux", "inspect", "replay", "simulate"}
+
+// runCommand dispatches a subcommand given as positional arguments
+func runCommand(opts options, cfg Config) error {
+	switch opts.Args[0] {
+	case "export":
+		if len(opts.Args) < 2 {
+			return fmt.Errorf("export: missing format (gif, cast)")
+		}
+		switch opts.Args[1] {
+		case "gif":
+			return runExportGIF(opts)
+		case "cast":
+			return runExportCast(opts)
+		default:
+			return fmt.Errorf("export: unknown format %q", opts.Args[1])
+		}
+	case "tmux":
+		return runTmuxCommand(opts, cfg)
+	case "inspect":
+		return runInspectCommand(opts, cfg)
+	case "replay":
+		return runReplayCommand(opts, cfg)
+	case "simulate":
+		return runSimulateCommand(opts, cfg)
+	default:
+		return fmt.Errorf("unknown command %q", opts.Args[0])
+	}
+}
+
+// runExportGIF handles "statusline export gif"
+func runExportGIF(opts options) error {
+	o := gifOptions{
+		Width:    opts.Width,
+		Scale:    opts.Scale,
+		FPS:      opts.FPS,
+		Duration: opts.Duration,
+		Start:    opts.At,
+		Theme:    currentTheme(),
+	}
+	if o.Width == 0 {
+		o.Width = defaultTrackWidth()
+	}
+	if o.Scale == 0 {
+		o.Scale = 1
+	}
+	if o.FPS == 0 {
+		o.FPS = 4 // One sample per 250ms sprite frame
+	}
+	if o.Duration == 0 {
+		o.Duration = animationCycle(o.Width)
+	}
+	if o.Start.IsZero() {
+		// Start of the Unix epoch keeps exports byte-for-byte reproducible
+		o.Start = time.UnixMilli(0)
+	}
+
+	return writeOutput(opts.Out, "horse.gif", func(w io.Writer) error {
+		return exportGIF(w, o)
+	})
+}
+
+// runExportCast handles "statusline export cast"
+func runExportCast(opts options) error {
+	o := castOptions{
+		Cols:     opts.Width,
+		FPS:      opts.FPS,
+		Duration: opts.Duration,
+		Start:    opts.At,
+	}
+	if o.Cols == 0 {
+		o.Cols = defaultTrackWidth()
+	}
+	if o.FPS == 0 {
+		o.FPS = 10 // Same 100ms tick as --animate
+	}
+	if o.Duration == 0 {
+		o.Duration = animationCycle(o.Cols)
+	}
+	if o.Start.IsZero() {
+		o.Start = time.UnixMilli(0)
+	}
+
+	return writeOutput(opts.Out, "horse.cast", func(w io.Writer) error {
+		return exportCast(w, o)
+	})
+}
+
+// runTmuxCommand handles "statusline tmux", rendering the cached last
+// input as tmux markup so it can run from status-right without stdin
+func runTmuxCommand(opts options, cfg Config) error {
+	opts.Format = formatTmux
+
+	data, err := loadLastInput()
+	data = trimNullBytes(data)
+	if err != nil || len(data) == 0 {
+		// Nothing cached yet, still render the horse
+		renderStatusLineMulti(nil, nil, nil, opts, cfg)
+		return nil
+	}
+
+	var input StatusLineInput
+	_ = json.Unmarshal(data, &input)
+	renderStatusLineMulti(&input, nil, nil, opts, cfg)
+	return nil
+}
+
+// runInspectCommand handles "statusline inspect", reading the payload
+// like a normal render and reporting how it was parsed
+func runInspectCommand(opts options, cfg Config) error {
+	source, err := openInput(opts.InputPath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	data, readErr := readInput(source, cfg.Input.MaxBytes, cfg.Input.timeout())
+	data = trimNullBytes(data)
+	report := inspectPayload(data)
+	report.ReadErr = readErr
+	writeInspectReport(os.Stdout, data, report, cfg, opts.now())
+
+	if report.Syntax != nil {
+		return fmt.Errorf("inspect: payload is not valid JSON")
+	}
+	return nil
+}
+
+// writeOutput runs write against the named file, stdout for "-",
+// or defaultName when no --out was given
+func writeOutput(path, defaultName string, write func(w io.Writer) error) error {
+	if path == "" {
+		path = defaultName
+	}
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
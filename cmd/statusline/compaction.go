@@ -0,0 +1,54 @@
+// Package main provides compaction detection: a session whose input token
+// total falls far below the previous call was compacted or cleared
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Compaction detection thresholds
+const (
+	compactionDropRatio = 0.5    // The total must fall below this share of the previous one
+	compactionMinDrop   = 10_000 // and by at least this many tokens
+)
+
+// compactionEvent records one detected compaction
+type compactionEvent struct {
+	At   time.Time `json:"at"`
+	From int       `json:"from"` // TotalInputTokens before
+	To   int       `json:"to"`   // TotalInputTokens after
+}
+
+// isCompaction reports whether an input token total of cur after prev
+// means the context was compacted or cleared
+func isCompaction(prev, cur int) bool {
+	return prev-cur >= compactionMinDrop && float64(cur) < float64(prev)*compactionDropRatio
+}
+
+// detectCompaction compares the input token total with the previous call
+// and records a compaction event when it collapsed
+func detectCompaction(st *sessionState, input *StatusLineInput, now time.Time) {
+	if input == nil {
+		return
+	}
+	cur := input.ContextWindow.TotalInputTokens
+	if st.Calls > 0 && isCompaction(st.InputTokens, cur) {
+		st.Compactions++
+		st.LastCompaction = compactionEvent{At: now, From: st.InputTokens, To: cur}
+	}
+	st.InputTokens = cur
+}
+
+// compactedAt reports whether the session was compacted by the call at now
+func (s *sessionState) compactedAt(now time.Time) bool {
+	return s != nil && !s.LastCompaction.At.IsZero() && s.LastCompaction.At.Equal(now)
+}
+
+// compactionSegment shows how often the session was compacted
+func compactionSegment(ctx *segmentContext) segment {
+	if ctx.Status.Compactions == 0 {
+		return segment{Color: colorDefault}
+	}
+	return segment{Text: fmt.Sprintf("🗜 %d", ctx.Status.Compactions), Color: colorDefault}
+}
@@ -0,0 +1,47 @@
+// Package main provides tests for compaction detection and the whip reaction
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCompaction(t *testing.T) {
+	assert.True(t, isCompaction(180_000, 30_000))
+	assert.False(t, isCompaction(180_000, 120_000), "a small dip is not a compaction")
+	assert.False(t, isCompaction(8_000, 1_000), "tiny totals never count")
+	assert.False(t, isCompaction(30_000, 180_000))
+}
+
+func TestDetectCompaction(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	updateSessionState(&st, testPayload{Input: 150_000}.build(), t0, nil)
+	assert.Zero(t, st.Compactions, "the first call has nothing to compare against")
+
+	compacted := t0.Add(2 * time.Second)
+	updateSessionState(&st, testPayload{Input: 20_000}.build(), compacted, nil)
+	assert.Equal(t, 1, st.Compactions)
+	assert.Equal(t, compactionEvent{At: compacted, From: 150_000, To: 20_000}, st.LastCompaction)
+	assert.True(t, st.compactedAt(compacted))
+	assert.Equal(t, gaitWhip, st.Gait, "the whip cracks even mid-hold")
+
+	updateSessionState(&st, testPayload{Input: 25_000}.build(), t0.Add(4*time.Second), nil)
+	assert.Equal(t, 1, st.Compactions)
+	assert.False(t, st.compactedAt(t0.Add(4*time.Second)))
+	assert.Equal(t, gaitWhip, st.Gait, "the whip plays out first")
+
+	updateSessionState(&st, testPayload{Input: 30_000}.build(), t0.Add(6*time.Second), nil)
+	assert.NotEqual(t, gaitWhip, st.Gait)
+
+	var none *sessionState
+	assert.False(t, none.compactedAt(t0))
+}
+
+func TestCompactionSegment(t *testing.T) {
+	assert.Empty(t, compactionSegment(&segmentContext{}).Text)
+	ctx := &segmentContext{Status: statusModel{Compactions: 2}}
+	assert.Equal(t, "🗜 2", compactionSegment(ctx).Text)
+}
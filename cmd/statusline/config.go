@@ -0,0 +1,144 @@
+// Package main provides the user configuration file
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Layouts selectable in the config file or with --layout
+const (
+	layoutFull    = "full"    // Four-row track with segments below
+	layoutCompact = "compact" // Single row with the one-row sprite
+	layoutAuto    = "auto"    // Compact when the terminal is small
+)
+
+// configEnvVar overrides the config file location
+const configEnvVar = "CLAUDE_RIDE_CONFIG"
+
+// Config is the user configuration read from config.json
+// Every field is optional; zero values fall back to defaultConfig
+type Config struct {
+	Layout   string   `json:"layout"`   // full, compact or auto
+	Segments []string `json:"segments"` // Enabled segments, in display order
+
+	// Template segments usable by name in Segments
+	CustomSegments map[string]customSegmentConfig `json:"custom_segments"`
+
+	Path  pathConfig  `json:"path"`  // Path segment display and redaction
+	Input inputConfig `json:"input"` // Payload size cap and read deadline
+
+	Record recordConfig `json:"record"` // Capture payloads for "statusline replay"
+	ETA    etaConfig    `json:"eta"`    // Context fill forecast marker
+	Alerts alertsConfig `json:"alerts"` // Alert rules and delivery channels
+
+	Progress string      `json:"progress"` // OSC 9;4 progress metric: off, context or rate_limit
+	Title    titleConfig `json:"title"`    // OSC 0/2 window title
+
+	Hyperlinks string `json:"hyperlinks"` // OSC 8 links on path segments: off, auto or on
+}
+
+// defaultConfig returns the configuration used when no file exists
+// It renders the baseline four-row horse without segments; the config
+// file opts in to segments and the compact layout
+func defaultConfig() Config {
+	return Config{
+		Layout:   layoutFull,
+		Path:     defaultPathConfig(),
+		Input:    defaultInputConfig(),
+		Record:   defaultRecordConfig(),
+		ETA:      defaultETAConfig(),
+		Alerts:   defaultAlertsConfig(),
+		Progress: progressOff,
+		Title:    defaultTitleConfig(),
+
+		Hyperlinks: hyperlinksOff,
+	}
+}
+
+// configPath returns the config file location
+// The CLAUDE_RIDE_CONFIG environment variable takes precedence
+func configPath() string {
+	if p := os.Getenv(configEnvVar); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "claude-ride-with-whip", "config.json")
+}
+
+// loadConfig reads the config file at path, or the default location when
+// path is empty. A missing file yields defaultConfig without an error
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		path = configPath()
+	}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	// Decode over the defaults so omitted fields keep their default
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return defaultConfig(), fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return defaultConfig(), fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate checks field values that JSON decoding cannot
+func (c Config) validate() error {
+	switch c.Layout {
+	case layoutFull, layoutCompact, layoutAuto:
+	default:
+		return fmt.Errorf("invalid layout %q: want full, compact or auto", c.Layout)
+	}
+	for _, name := range c.Segments {
+		if _, ok := lookupSegment(name, c); !ok {
+			return fmt.Errorf("unknown segment %q", name)
+		}
+	}
+	for name, custom := range c.CustomSegments {
+		if _, err := parseSegmentTemplate(custom.Template); err != nil {
+			return fmt.Errorf("custom segment %q: %w", name, err)
+		}
+	}
+	if err := c.Input.validate(); err != nil {
+		return err
+	}
+	if err := c.Record.validate(); err != nil {
+		return err
+	}
+	if err := c.ETA.validate(); err != nil {
+		return err
+	}
+	if err := c.Alerts.validate(); err != nil {
+		return err
+	}
+	if err := validateProgress(c.Progress); err != nil {
+		return err
+	}
+	if err := c.Title.validate(); err != nil {
+		return err
+	}
+	if err := validateHyperlinks(c.Hyperlinks); err != nil {
+		return err
+	}
+	return c.Path.validate()
+}
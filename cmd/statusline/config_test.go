@@ -0,0 +1,89 @@
+// Package main provides tests for the user configuration file
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_MissingFileUsesDefaults(t *testing.T) {
+	cfg, err := loadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(t, err)
+	assert.Equal(t, defaultConfig(), cfg)
+}
+
+func TestDefaultConfig_BaselineOutput(t *testing.T) {
+	// Users without a config file keep the original four-row horse
+	cfg := defaultConfig()
+	assert.Equal(t, layoutFull, cfg.Layout)
+	assert.Empty(t, cfg.Segments)
+}
+
+func TestLoadConfig_PartialFileKeepsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"layout": "compact"}`)
+
+	cfg, err := loadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, layoutCompact, cfg.Layout)
+	assert.Equal(t, defaultConfig().Path, cfg.Path, "omitted fields keep defaults")
+}
+
+func TestLoadConfig_AlertRulesReplaceDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"alerts": {"rules": [{"name": "budget", "metric": "cost", "threshold": 5, "level": "error"}]}}`)
+
+	cfg, err := loadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, []alertRule{{Name: "budget", Metric: metricCost, Threshold: 5, Level: levelError}}, cfg.Alerts.Rules,
+		"user rules inherit nothing from the default rules")
+	assert.Equal(t, defaultAlertsConfig().Channels, cfg.Alerts.Channels)
+
+	writeFile(t, path, `{"alerts": {"rules": [{"name": "budget", "metric": "cost", "level": "error"}]}}`)
+	cfg, err = loadConfig(path)
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Alerts.Rules[0].Threshold, "an omitted threshold is not the default 80")
+
+	writeFile(t, path, `{"alerts": {"flash_seconds": 2}}`)
+	cfg, err = loadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, defaultAlertRules(), cfg.Alerts.Rules, "omitted rules keep the defaults")
+}
+
+func TestLoadConfig_EnvOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.json")
+	writeFile(t, path, `{"segments": ["git"]}`)
+	t.Setenv(configEnvVar, path)
+
+	cfg, err := loadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"git"}, cfg.Segments)
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "malformed JSON", content: `{"layout": `},
+		{name: "unknown layout", content: `{"layout": "sideways"}`},
+		{name: "unknown segment", content: `{"segments": ["weather"]}`},
+		{name: "unknown alert metric", content: `{"alerts": {"rules": [{"name": "x", "metric": "mood", "threshold": 1, "level": "error"}]}}`},
+		{name: "alert rule without level", content: `{"alerts": {"rules": [{"name": "budget", "metric": "cost", "threshold": 5}]}}`},
+		{name: "unknown alert channel", content: `{"alerts": {"channels": ["pager"]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			writeFile(t, path, tt.content)
+
+			cfg, err := loadConfig(path)
+			assert.Error(t, err)
+			assert.Equal(t, defaultConfig(), cfg, "invalid configs fall back to defaults")
+		})
+	}
+}
@@ -2,7 +2,52 @@
 
 package main
 
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// resizeNeedsPolling reports whether the terminal size must be polled
+// Unix-like systems deliver SIGWINCH, so no polling is required
+const resizeNeedsPolling = false
+
 // Console initialization for non-Windows platforms
 func initConsole() {
 	// UTF-8 is the default on Unix-like systems
 }
+
+// terminalSize returns the size of the attached terminal in cells
+// Falls back to the COLUMNS and LINES environment variables
+func terminalSize() (cols, rows int, ok bool) {
+	for _, f := range []*os.File{os.Stdout, os.Stderr, os.Stdin} {
+		var ws struct {
+			Row, Col, XPixel, YPixel uint16
+		}
+		_, _, errno := syscall.Syscall(
+			syscall.SYS_IOCTL,
+			f.Fd(),
+			uintptr(syscall.TIOCGWINSZ),
+			uintptr(unsafe.Pointer(&ws)),
+		)
+		if errno == 0 && ws.Col > 0 {
+			return int(ws.Col), int(ws.Row), true
+		}
+	}
+	return envTerminalSize()
+}
+
+// notifyResize returns a channel that receives a value on every SIGWINCH
+// The returned function stops the notifications
+func notifyResize() (<-chan os.Signal, func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	return ch, func() { signal.Stop(ch) }
+}
+
+// openTTY opens the controlling terminal for writing, bypassing stdout
+// which the statusline host captures
+func openTTY() (*os.File, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
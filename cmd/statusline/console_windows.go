@@ -3,10 +3,15 @@
 package main
 
 import (
+	"os"
 	"syscall"
 	"unsafe"
 )
 
+// resizeNeedsPolling reports whether the terminal size must be polled
+// Windows consoles have no SIGWINCH, so the size is checked on every tick
+const resizeNeedsPolling = true
+
 // Windows console initialization for UTF-8 and ANSI support
 func initConsole() {
 	// Set UTF-8 code page
@@ -34,3 +39,42 @@ func initConsole() {
 	getConsoleMode.Call(stdout, uintptr(unsafe.Pointer(&consoleMode)))
 	setConsoleMode.Call(stdout, uintptr(consoleMode|ENABLE_VIRTUAL_TERMINAL_PROCESSING))
 }
+
+// terminalSize returns the size of the console window in cells
+// Falls back to the COLUMNS and LINES environment variables
+func terminalSize() (cols, rows int, ok bool) {
+	kernel32 := syscall.MustLoadDLL("kernel32.dll")
+	getConsoleScreenBufferInfo := kernel32.MustFindProc("GetConsoleScreenBufferInfo")
+
+	// CONSOLE_SCREEN_BUFFER_INFO layout
+	var info struct {
+		SizeX, SizeY                           int16
+		CursorX, CursorY                       int16
+		Attributes                             uint16
+		WindowLeft, WindowTop                  int16
+		WindowRight, WindowBottom              int16
+		MaximumWindowSizeX, MaximumWindowSizeY int16
+	}
+
+	r, _, _ := getConsoleScreenBufferInfo.Call(uintptr(syscall.Stdout), uintptr(unsafe.Pointer(&info)))
+	if r != 0 {
+		cols = int(info.WindowRight-info.WindowLeft) + 1
+		rows = int(info.WindowBottom-info.WindowTop) + 1
+		if cols > 0 {
+			return cols, rows, true
+		}
+	}
+	return envTerminalSize()
+}
+
+// notifyResize returns a nil channel because Windows has no resize signal
+// Callers poll terminalSize instead (see resizeNeedsPolling)
+func notifyResize() (<-chan os.Signal, func()) {
+	return nil, func() {}
+}
+
+// openTTY opens the console output for writing, bypassing stdout
+// which the statusline host captures
+func openTTY() (*os.File, error) {
+	return os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+}
@@ -0,0 +1,63 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// LockFileEx flags and the error returned for a lock held elsewhere
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	errorLockViolation      = syscall.Errno(33)
+)
+
+var (
+	procLockFileEx   = syscall.MustLoadDLL("kernel32.dll").MustFindProc("LockFileEx")
+	procUnlockFileEx = syscall.MustLoadDLL("kernel32.dll").MustFindProc("UnlockFileEx")
+)
+
+// tryLockFile takes an exclusive lock on the first byte of f without
+// blocking. It returns errLockBusy when another process holds the lock
+func tryLockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	r, _, err := syscall.Syscall6(
+		procLockFileEx.Addr(),
+		6,
+		f.Fd(),
+		lockfileExclusiveLock|lockfileFailImmediately,
+		0,
+		1,
+		0,
+		uintptr(unsafe.Pointer(&ol)),
+	)
+	if r != 0 {
+		return nil
+	}
+	if err == errorLockViolation || err == syscall.ERROR_IO_PENDING {
+		return errLockBusy
+	}
+	return err
+}
+
+// unlockFile releases a lock taken by tryLockFile
+func unlockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	r, _, err := syscall.Syscall6(
+		procUnlockFileEx.Addr(),
+		5,
+		f.Fd(),
+		0,
+		1,
+		0,
+		uintptr(unsafe.Pointer(&ol)),
+		0,
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
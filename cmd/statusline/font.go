@@ -0,0 +1,197 @@
+// Package main provides a tiny built-in bitmap font for image exports
+package main
+
+// Font cell size in pixels; wide characters occupy two cells
+const (
+	fontCellWidth  = 8
+	fontCellHeight = 16
+)
+
+// fontGlyphs maps the characters used by the horse animation to bitmaps
+// Each row is a string where '#' is a lit pixel; rows are fontCellWidth
+// wide, or twice that for wide (2-cell) characters
+var fontGlyphs = map[rune][]string{
+	' ': blankGlyph(1),
+	'.': {
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"   ##   ",
+		"   ##   ",
+		"        ",
+		"        ",
+		"        ",
+	},
+	')': {
+		"        ",
+		"        ",
+		"  ##    ",
+		"   ##   ",
+		"    ##  ",
+		"    ##  ",
+		"     ## ",
+		"     ## ",
+		"     ## ",
+		"     ## ",
+		"    ##  ",
+		"    ##  ",
+		"   ##   ",
+		"  ##    ",
+		"        ",
+		"        ",
+	},
+	'~': {
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		" ###  # ",
+		"## ## ##",
+		"#   ### ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+	},
+	'/': {
+		"        ",
+		"        ",
+		"      ##",
+		"      # ",
+		"     ## ",
+		"     #  ",
+		"    ##  ",
+		"    #   ",
+		"   ##   ",
+		"   #    ",
+		"  ##    ",
+		"  #     ",
+		" ##     ",
+		" #      ",
+		"        ",
+		"        ",
+	},
+	'\\': {
+		"        ",
+		"        ",
+		"##      ",
+		" #      ",
+		" ##     ",
+		"  #     ",
+		"  ##    ",
+		"   #    ",
+		"   ##   ",
+		"    #   ",
+		"    ##  ",
+		"     #  ",
+		"     ## ",
+		"      # ",
+		"        ",
+		"        ",
+	},
+	'ﾉ': {
+		"        ",
+		"        ",
+		"        ",
+		"      # ",
+		"      # ",
+		"      # ",
+		"     ## ",
+		"     #  ",
+		"    ##  ",
+		"    #   ",
+		"   ##   ",
+		"  ##    ",
+		" ##     ",
+		"##      ",
+		"        ",
+		"        ",
+	},
+	'⏜': {
+		"        ",
+		"        ",
+		"        ",
+		"  ####  ",
+		" ##  ## ",
+		"##    ##",
+		"#      #",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+		"        ",
+	},
+	'🐴': {
+		"                ",
+		"      #  #      ",
+		"     ## ##      ",
+		"    ########    ",
+		"   ##########   ",
+		"  ###### ####   ",
+		"  ###########   ",
+		" #############  ",
+		"############### ",
+		"####  ######### ",
+		" ##   ######### ",
+		"       ######## ",
+		"       ######## ",
+		"      ######### ",
+		"      ######### ",
+		"                ",
+	},
+}
+
+// blankGlyph returns an empty bitmap spanning the given number of cells
+func blankGlyph(cells int) []string {
+	row := make([]byte, cells*fontCellWidth)
+	for i := range row {
+		row[i] = ' '
+	}
+	rows := make([]string, fontCellHeight)
+	for i := range rows {
+		rows[i] = string(row)
+	}
+	return rows
+}
+
+// boxGlyph returns a hollow box used for characters missing from the font
+func boxGlyph(cells int) []string {
+	width := cells * fontCellWidth
+	rows := blankGlyph(cells)
+	for y := 2; y < fontCellHeight-2; y++ {
+		row := []byte(rows[y])
+		for x := 1; x < width-1; x++ {
+			if y == 2 || y == fontCellHeight-3 || x == 1 || x == width-2 {
+				row[x] = '#'
+			}
+		}
+		rows[y] = string(row)
+	}
+	return rows
+}
+
+// glyphFor returns the bitmap for a character spanning the given cells
+// Unknown characters fall back to a hollow box of the same width
+func glyphFor(ch rune, cells int) []string {
+	if g, ok := fontGlyphs[ch]; ok && len(g[0]) == cells*fontCellWidth {
+		return g
+	}
+	return boxGlyph(cells)
+}
@@ -0,0 +1,182 @@
+// Package main provides the context window forecast: a burn rate fitted
+// to the context history of the session and the time left until it fills
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Forecast tuning
+const (
+	contextHistorySize = 30               // Samples kept per session
+	forecastMinSamples = 3                // Fewer samples give no forecast
+	forecastMinSpan    = 10 * time.Second // Samples must cover at least this long
+	forecastWarning    = 10 * time.Minute // Segment turns orange below this
+	forecastMarker     = '|'              // Drawn on the track at the projected fill point
+)
+
+// etaConfig controls the forecast marker on the track
+type etaConfig struct {
+	Marker         bool `json:"marker"`          // Draw the marker at all
+	HorizonMinutes int  `json:"horizon_minutes"` // Time the whole track stands for
+}
+
+// defaultETAConfig returns the forecast defaults
+func defaultETAConfig() etaConfig {
+	return etaConfig{Marker: true, HorizonMinutes: 30}
+}
+
+// validate rejects a horizon that cannot scale the track
+func (c etaConfig) validate() error {
+	if c.HorizonMinutes <= 0 {
+		return fmt.Errorf("eta horizon_minutes must be positive")
+	}
+	return nil
+}
+
+// horizon returns the time the whole track stands for
+func (c etaConfig) horizon() time.Duration {
+	return time.Duration(c.HorizonMinutes) * time.Minute
+}
+
+// contextSample is the context usage seen at one call
+type contextSample struct {
+	At   time.Time `json:"at"`
+	Used int       `json:"used"`
+}
+
+// contextForecast is the projection of when the context window fills
+type contextForecast struct {
+	TokensPerMinute float64       `json:"tokens_per_minute"`
+	ETA             time.Duration `json:"eta_ns"`
+	TurnsLeft       int           `json:"turns_left"` // Growing calls left at the average growth
+}
+
+// recordContext appends the context usage of a payload to the history
+// A compaction or clear makes the earlier samples useless for the burn
+// rate and starts a new history; small dips, such as a cache entry
+// expiring, keep it
+func recordContext(st *sessionState, input *StatusLineInput, now time.Time) {
+	usage := contextUsage(input)
+	if usage.WindowSize == 0 {
+		return
+	}
+	st.ContextWindow = usage.WindowSize
+	if n := len(st.ContextHistory); n > 0 && isCompaction(st.ContextHistory[n-1].Used, usage.UsedTokens) {
+		st.ContextHistory = nil
+	}
+	st.ContextHistory = append(st.ContextHistory, contextSample{At: now, Used: usage.UsedTokens})
+	if extra := len(st.ContextHistory) - contextHistorySize; extra > 0 {
+		st.ContextHistory = append([]contextSample(nil), st.ContextHistory[extra:]...)
+	}
+}
+
+// forecast fits a least-squares burn rate to the context history and
+// projects when the window fills. It returns nil while the history is too
+// short or the context is not growing
+func (s *sessionState) forecast() *contextForecast {
+	if s == nil || s.ContextWindow == 0 || len(s.ContextHistory) < forecastMinSamples {
+		return nil
+	}
+	h := s.ContextHistory
+	first, last := h[0], h[len(h)-1]
+	if last.At.Sub(first.At) < forecastMinSpan {
+		return nil
+	}
+
+	// Slope of used tokens over seconds since the first sample
+	n := float64(len(h))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range h {
+		x := sample.At.Sub(first.At).Seconds()
+		y := float64(sample.Used)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return nil
+	}
+	perSecond := (n*sumXY - sumX*sumY) / denominator
+	if perSecond <= 0 {
+		return nil
+	}
+
+	left := max(s.ContextWindow-last.Used, 0)
+	f := &contextForecast{
+		TokensPerMinute: perSecond * 60,
+		ETA:             time.Duration(float64(left) / perSecond * float64(time.Second)),
+	}
+
+	// Average growth of the calls that grew the context. Small dips stay in
+	// the history, so the net growth can be zero or negative while the
+	// fitted slope is positive; no turn count is given then
+	growing := 0
+	for i := 1; i < len(h); i++ {
+		if h[i].Used > h[i-1].Used {
+			growing++
+		}
+	}
+	if growing > 0 {
+		if perTurn := float64(last.Used-first.Used) / float64(growing); perTurn > 0 {
+			f.TurnsLeft = int(float64(left) / perTurn)
+		}
+	}
+	return f
+}
+
+// forecastSegment shows the time and turns left before the context fills
+func forecastSegment(ctx *segmentContext) segment {
+	f := ctx.Status.Forecast
+	if f == nil {
+		return segment{Color: colorDefault}
+	}
+	color := colorDefault
+	if f.ETA < forecastWarning {
+		color = 214 // Orange
+	}
+	text := "⏳ " + formatDuration(f.ETA)
+	if f.TurnsLeft > 0 {
+		text += fmt.Sprintf(" ~%d turns", f.TurnsLeft)
+	}
+	return segment{Text: text, Color: color}
+}
+
+// forecastColumn returns the track column of the fill point on a fixed
+// timeline: the horse runs right to left, so the right end of the track is
+// now and column 0 is the horizon. The marker does not follow the horse; it
+// moves right as the fill approaches. It returns -1 when the fill point is
+// beyond the horizon
+func forecastColumn(f *contextForecast, maxPos int, horizon time.Duration) int {
+	if f == nil || horizon <= 0 || f.ETA > horizon || maxPos <= 0 {
+		return -1
+	}
+	return maxPos - int(math.Round(float64(maxPos)*float64(f.ETA)/float64(horizon)))
+}
+
+// drawForecastMarker puts the marker on every track row whose cell at col
+// is a dot, so it never overwrites the sprite
+func drawForecastMarker(lines []string, col int) []string {
+	if col < 0 {
+		return lines
+	}
+	marked := make([]string, len(lines))
+	for i, line := range lines {
+		var b strings.Builder
+		cell := 0
+		for _, ch := range line {
+			if cell == col && ch == '.' {
+				ch = forecastMarker
+			}
+			b.WriteRune(ch)
+			cell += StringWidth(string(ch))
+		}
+		marked[i] = b.String()
+	}
+	return marked
+}
@@ -0,0 +1,117 @@
+// Package main provides tests for the context window forecast
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecast_FitsBurnRate(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	assert.Nil(t, st.forecast())
+
+	// 1000 tokens every 10s: 6000 tokens a minute
+	for i := range 5 {
+		updateSessionState(&st, testPayload{Used: 40_000 + i*1000, Window: 100_000}.build(), t0.Add(time.Duration(i)*10*time.Second), nil)
+	}
+	f := st.forecast()
+	if assert.NotNil(t, f) {
+		assert.InDelta(t, 6000, f.TokensPerMinute, 0.001)
+		assert.Equal(t, 560*time.Second, f.ETA, "56k tokens left at 100 tok/s")
+		assert.Equal(t, 56, f.TurnsLeft)
+	}
+}
+
+func TestForecast_NeedsGrowthAndHistory(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	updateSessionState(&st, testPayload{Used: 40_000, Window: 100_000}.build(), t0, nil)
+	updateSessionState(&st, testPayload{Used: 41_000, Window: 100_000}.build(), t0.Add(10*time.Second), nil)
+	assert.Nil(t, st.forecast(), "two samples are too few")
+
+	var flat sessionState
+	for i := range 4 {
+		updateSessionState(&flat, testPayload{Used: 40_000, Window: 100_000}.build(), t0.Add(time.Duration(i)*10*time.Second), nil)
+	}
+	assert.Nil(t, flat.forecast(), "a flat context never fills")
+}
+
+func TestForecast_NoTurnsWithoutNetGrowth(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	// Rising overall, but the last dip lands back on the first sample
+	for i, used := range []int{50_000, 49_000, 49_000, 60_000, 61_000, 62_000, 50_000} {
+		updateSessionState(&st, testPayload{Used: used, Window: 100_000}.build(), t0.Add(time.Duration(i)*10*time.Second), nil)
+	}
+	require.Len(t, st.ContextHistory, 7, "no dip is a compaction")
+
+	f := st.forecast()
+	if assert.NotNil(t, f, "the fitted slope is positive") {
+		assert.Zero(t, f.TurnsLeft, "no turn count without net growth")
+	}
+}
+
+func TestRecordContext_ResetsOnCompaction(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	for i := range 3 {
+		recordContext(&st, testPayload{Used: 80_000 + i*1000, Window: 100_000}.build(), t0.Add(time.Duration(i)*time.Second))
+	}
+	recordContext(&st, testPayload{Used: 20_000, Window: 100_000}.build(), t0.Add(3*time.Second))
+	assert.Equal(t, []contextSample{{At: t0.Add(3 * time.Second), Used: 20_000}}, st.ContextHistory)
+
+	recordContext(&st, testPayload{Used: 19_000, Window: 100_000}.build(), t0.Add(3500*time.Millisecond))
+	assert.Len(t, st.ContextHistory, 2, "a small dip is not a compaction")
+
+	recordContext(&st, &StatusLineInput{}, t0.Add(4*time.Second))
+	assert.Len(t, st.ContextHistory, 2, "payloads without a window are ignored")
+
+	for i := range contextHistorySize + 5 {
+		recordContext(&st, testPayload{Used: 20_000 + i, Window: 100_000}.build(), t0.Add(time.Duration(5+i)*time.Second))
+	}
+	assert.Len(t, st.ContextHistory, contextHistorySize)
+}
+
+func TestForecastSegment(t *testing.T) {
+	assert.Empty(t, forecastSegment(&segmentContext{}).Text)
+
+	ctx := &segmentContext{Status: statusModel{Forecast: &contextForecast{ETA: 25 * time.Minute, TurnsLeft: 8}}}
+	seg := forecastSegment(ctx)
+	assert.Equal(t, "⏳ 25m ~8 turns", seg.Text)
+	assert.Equal(t, colorDefault, seg.Color)
+
+	ctx.Status.Forecast = &contextForecast{ETA: 90 * time.Second}
+	seg = forecastSegment(ctx)
+	assert.Equal(t, "⏳ 1m", seg.Text)
+	assert.Equal(t, 214, seg.Color, "orange when the fill is close")
+}
+
+func TestForecastColumn(t *testing.T) {
+	horizon := 30 * time.Minute
+
+	assert.Equal(t, -1, forecastColumn(nil, 60, horizon))
+	assert.Equal(t, -1, forecastColumn(&contextForecast{ETA: time.Hour}, 60, horizon), "beyond the horizon")
+	assert.Equal(t, 60, forecastColumn(&contextForecast{}, 60, horizon), "full now: the right end")
+	assert.Equal(t, 45, forecastColumn(&contextForecast{ETA: 7*time.Minute + 30*time.Second}, 60, horizon))
+	assert.Equal(t, 6, forecastColumn(&contextForecast{ETA: 27 * time.Minute}, 60, horizon))
+	assert.Equal(t, 0, forecastColumn(&contextForecast{ETA: horizon}, 60, horizon), "the horizon: the left end")
+}
+
+func TestDrawForecastMarker(t *testing.T) {
+	lines := drawHorse(horseState{Gait: gaitGallop, Position: 10, MaxPos: 75}, 95)
+	marked := drawForecastMarker(lines, 4)
+	for i, line := range marked {
+		assert.Equal(t, StringWidth(lines[i]), StringWidth(line))
+		assert.Equal(t, "....|", line[:5])
+	}
+
+	// The sprite is never overwritten
+	assert.Equal(t, lines[1], drawForecastMarker(lines, 10)[1])
+	assert.Equal(t, lines, drawForecastMarker(lines, -1))
+	assert.False(t, strings.ContainsRune(strings.Join(drawForecastMarker(lines, -1), ""), forecastMarker))
+}
@@ -116,3 +116,31 @@ func GetHorseSprite(frameIndex int) []string {
 func NumFrames() int {
 	return len(HorseFrames)
 }
+
+// MaxSpriteWidth returns the widest sprite line across all frames in terminal cells
+func MaxSpriteWidth() int {
+	maxWidth := 0
+	for _, frame := range HorseSprite {
+		for _, line := range frame {
+			// Use terminal cell width (emoji = 2 cells, not 1)
+			if w := StringWidth(line); w > maxWidth {
+				maxWidth = w
+			}
+		}
+	}
+	return maxWidth
+}
+
+// HorseSpriteCompact is the one-row sprite used by the compact layout
+// Legs follow the head instead of sitting on a second row
+var HorseSpriteCompact = []string{
+	"🐴ﾉﾉ~",
+	"🐴/\\~",
+	"🐴\\/~",
+	"🐴ﾉ/~",
+}
+
+// GetCompactSprite returns the one-row sprite for a given frame
+func GetCompactSprite(frameIndex int) string {
+	return HorseSpriteCompact[frameIndex%len(HorseSpriteCompact)]
+}
@@ -0,0 +1,310 @@
+// Package main provides the gait state machine: named sprite sequences
+// with their own timing, and the rules for moving between them
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// Gait names
+const (
+	gaitRest   = "rest"   // Standing still, nothing streamed for a while
+	gaitWalk   = "walk"   // Barely any output
+	gaitTrot   = "trot"   // Slow output
+	gaitCanter = "canter" // Steady output
+	gaitGallop = "gallop" // Fast output, and the classic stateless animation
+	gaitRear   = "rear"   // One-shot reaction to a new error alert
+	gaitWhip   = "whip"   // One-shot whip crack after a compaction
+)
+
+// gaitSequence is one named sprite sequence and its timing
+// Periods are in animation clock time, which already runs faster or
+// slower with the output throughput
+type gaitSequence struct {
+	Frames      [][]string    // Two-row sprites for the full layout
+	Compact     []string      // One-row sprites for the compact layout
+	FramePeriod time.Duration // Time per sprite frame
+	StepPeriod  time.Duration // Time per track step, 0 stands in place
+	Once        bool          // Play once and hold the last frame
+	MinHold     time.Duration // Wall time before the gait may change again
+	Rank        int           // Place on the speed ladder
+}
+
+// gaitSequences holds every gait; gallop is the original HorseSprite cycle
+var gaitSequences = map[string]gaitSequence{
+	gaitRest: {
+		Frames: [][]string{
+			{"🐴⏜))~", " ﾉﾉ ﾉﾉ"},
+			{"🐴⏜)) ~", " ﾉﾉ ﾉﾉ"},
+		},
+		Compact:     []string{"🐴ﾉﾉ~", "🐴ﾉﾉ "},
+		FramePeriod: time.Second,
+		MinHold:     2 * time.Second,
+		Rank:        0,
+	},
+	gaitWalk: {
+		Frames: [][]string{
+			{"🐴⏜))~", " ﾉﾉ ﾉﾉ"},
+			{"🐴⏜))~", " |ﾉ ﾉ|"},
+			{"🐴⏜))~", " ﾉﾉ ﾉﾉ"},
+			{"🐴⏜))~", " ﾉ| |ﾉ"},
+		},
+		Compact:     []string{"🐴ﾉﾉ~", "🐴|ﾉ~", "🐴ﾉﾉ~", "🐴ﾉ|~"},
+		FramePeriod: 400 * time.Millisecond,
+		StepPeriod:  time.Second,
+		MinHold:     2 * time.Second,
+		Rank:        1,
+	},
+	gaitTrot: {
+		Frames: [][]string{
+			{"🐴⏜))~~", " /ﾉ ﾉ\\"},
+			{"🐴⏜))~~", " ﾉﾉ ﾉﾉ"},
+			{"🐴⏜))~~", " ﾉ\\ /ﾉ"},
+			{"🐴⏜))~~", " ﾉﾉ ﾉﾉ"},
+		},
+		Compact:     []string{"🐴/ﾉ~", "🐴ﾉﾉ~", "🐴ﾉ\\~", "🐴ﾉﾉ~"},
+		FramePeriod: 300 * time.Millisecond,
+		StepPeriod:  700 * time.Millisecond,
+		MinHold:     2 * time.Second,
+		Rank:        2,
+	},
+	gaitCanter: {
+		Frames: [][]string{
+			{"🐴⏜))~~", " / \\ ﾉﾉ"},
+			{"🐴⏜))~~", " ﾉﾉ  //"},
+			{"🐴⏜))~~~", "  \\\\ ﾉﾉ"},
+		},
+		Compact:     []string{"🐴/\\~", "🐴ﾉ/~", "🐴\\ﾉ~"},
+		FramePeriod: 250 * time.Millisecond,
+		StepPeriod:  600 * time.Millisecond,
+		MinHold:     2 * time.Second,
+		Rank:        3,
+	},
+	gaitGallop: {
+		Frames:      HorseSprite,
+		Compact:     HorseSpriteCompact,
+		FramePeriod: 250 * time.Millisecond,
+		StepPeriod:  500 * time.Millisecond,
+		MinHold:     2 * time.Second,
+		Rank:        4,
+	},
+	gaitRear: {
+		Frames: [][]string{
+			{"🐴⏜))~", " ﾉﾉ ﾉﾉ"},
+			{"🐴^))~", "  ' ﾉﾉ"},
+			{"🐴^))~~", "    ﾉﾉ"},
+			{"🐴^))~", "  ' ﾉﾉ"},
+		},
+		Compact:     []string{"🐴ﾉﾉ~", "🐴'ﾉ~", "🐴 ﾉ~", "🐴'ﾉ~"},
+		FramePeriod: 300 * time.Millisecond,
+		Once:        true,
+		MinHold:     1200 * time.Millisecond, // The whole sequence
+		Rank:        -1,                      // Off the ladder, entered only on alerts
+	},
+	gaitWhip: {
+		Frames: [][]string{
+			{"🐴⏜))~", " ﾉﾉ ﾉﾉ"},
+			{"🐴⏜))~/", " ﾉﾉ ﾉﾉ"},
+			{"🐴⏜))~~", " ﾉﾉ ﾉﾉ"},
+			{"🐴⏜))~~*", " ﾉﾉ ﾉﾉ"},
+			{"🐴⏜))~*", " /ﾉ ﾉ\\"},
+			{"🐴⏜))~~", " /ﾉ ﾉ\\"},
+		},
+		Compact:     []string{"🐴ﾉﾉ~", "🐴ﾉﾉ/", "🐴ﾉﾉ~", "🐴ﾉﾉ*", "🐴/\\*", "🐴/\\~"},
+		FramePeriod: 500 * time.Millisecond,
+		StepPeriod:  300 * time.Millisecond, // The horse bolts after the crack
+		Once:        true,
+		MinHold:     3 * time.Second, // The whole sequence
+		Rank:        -1,              // Off the ladder, entered only on compactions
+	},
+}
+
+// gaitTransitions lists the gaits reachable from each gait in one change
+// The horse changes one rung of the ladder at a time; any gait may rear
+// or be whipped, and both return straight to the ladder
+var gaitTransitions = map[string][]string{
+	gaitRest:   {gaitWalk, gaitRear, gaitWhip},
+	gaitWalk:   {gaitRest, gaitTrot, gaitRear, gaitWhip},
+	gaitTrot:   {gaitWalk, gaitCanter, gaitRear, gaitWhip},
+	gaitCanter: {gaitTrot, gaitGallop, gaitRear, gaitWhip},
+	gaitGallop: {gaitCanter, gaitRear, gaitWhip},
+	gaitRear:   {gaitRest, gaitWalk, gaitTrot, gaitCanter, gaitGallop, gaitWhip},
+	gaitWhip:   {gaitRest, gaitWalk, gaitTrot, gaitCanter, gaitGallop, gaitRear},
+}
+
+// Thresholds that select the target gait
+const (
+	gaitRestAfter   = 30 * time.Second // No output this long: rest
+	gaitWalkBelow   = 5.0              // Output tok/s thresholds
+	gaitTrotBelow   = 20.0
+	gaitCanterBelow = 45.0
+)
+
+// gaitInputs are the session signals the state machine reacts to
+type gaitInputs struct {
+	Throughput  float64       // Output tokens per second
+	Measured    bool          // Throughput has at least one sample
+	Stalled     time.Duration // Time since the output last grew
+	AlertRaised bool          // An error alert appeared since the last call
+	Compacted   bool          // The context was compacted by this call
+}
+
+// targetGait returns the gait that fits the inputs
+// Without a throughput measurement the horse keeps its classic gallop
+func targetGait(in gaitInputs) string {
+	switch {
+	case in.Compacted:
+		return gaitWhip
+	case in.AlertRaised:
+		return gaitRear
+	case in.Stalled >= gaitRestAfter:
+		return gaitRest
+	case !in.Measured:
+		return gaitGallop
+	case in.Throughput < gaitWalkBelow:
+		return gaitWalk
+	case in.Throughput < gaitTrotBelow:
+		return gaitTrot
+	case in.Throughput < gaitCanterBelow:
+		return gaitCanter
+	}
+	return gaitGallop
+}
+
+// nextGait applies the transition rules: a gait is held for its MinHold,
+// then the horse moves straight to the target when allowed, otherwise one
+// rung along the ladder towards it. One-shot reactions cut the hold short,
+// since their event would be gone by the next call
+func nextGait(current string, held time.Duration, target string) string {
+	if current == target || (held < lookupGait(current).MinHold && !lookupGait(target).Once) {
+		return current
+	}
+	allowed := gaitTransitions[current]
+	if slices.Contains(allowed, target) {
+		return target
+	}
+
+	best, distance := current, rankDistance(current, target)
+	for _, next := range allowed {
+		if lookupGait(next).Once {
+			continue
+		}
+		if d := rankDistance(next, target); d < distance {
+			best, distance = next, d
+		}
+	}
+	return best
+}
+
+// rankDistance returns how many rungs apart two gaits are
+func rankDistance(a, b string) int {
+	d := lookupGait(a).Rank - lookupGait(b).Rank
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// lookupGait returns a gait sequence, falling back to the gallop
+func lookupGait(name string) gaitSequence {
+	if seq, ok := gaitSequences[name]; ok {
+		return seq
+	}
+	return gaitSequences[gaitGallop]
+}
+
+// frameAt returns the sprite frame after elapsed time in the gait
+func (g gaitSequence) frameAt(elapsed time.Duration) int {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	frame := int(elapsed / g.FramePeriod)
+	if g.Once {
+		return min(frame, len(g.Frames)-1)
+	}
+	return frame % len(g.Frames)
+}
+
+// stepsAt returns the track steps taken after elapsed time in the gait
+func (g gaitSequence) stepsAt(elapsed time.Duration) float64 {
+	if g.StepPeriod == 0 || elapsed < 0 {
+		return 0
+	}
+	return float64(elapsed) / float64(g.StepPeriod)
+}
+
+// stateAt returns the horse state after elapsed time in the gait, which
+// was entered with the horse startSteps along the track
+func (g gaitSequence) stateAt(name string, elapsed time.Duration, startSteps float64, frameWidth int) horseState {
+	maxPos := trackMaxPosition(frameWidth)
+	steps := int64(startSteps + g.stepsAt(elapsed))
+	return horseState{
+		Gait:     name,
+		Frame:    g.frameAt(elapsed),
+		Position: maxPos - int(steps%int64(maxPos)),
+		MaxPos:   maxPos,
+	}
+}
+
+// gaitSprite returns the two-row sprite of a gait frame
+func gaitSprite(name string, frame int) []string {
+	frames := lookupGait(name).Frames
+	return frames[frame%len(frames)]
+}
+
+// gaitCompactSprite returns the one-row sprite of a gait frame
+func gaitCompactSprite(name string, frame int) string {
+	frames := lookupGait(name).Compact
+	return frames[frame%len(frames)]
+}
+
+// advanceGait runs the state machine for a payload arriving at now
+// It runs after the animation clock has advanced to now and the alerts
+// of the payload were raised
+func advanceGait(st *sessionState, input *StatusLineInput, now time.Time) {
+	in := gaitInputs{
+		Throughput:  st.Throughput,
+		Measured:    st.ThroughputSamples > 0,
+		AlertRaised: st.raisedError(),
+		Compacted:   st.compactedAt(now),
+	}
+	if !st.OutputAt.IsZero() {
+		in.Stalled = now.Sub(st.OutputAt)
+	}
+
+	target := targetGait(in)
+	next := target
+	if st.Gait != "" {
+		next = nextGait(st.Gait, now.Sub(st.GaitSince), target)
+	}
+	if next == st.Gait {
+		return
+	}
+
+	// Keep the horse where it is on the track when the gait changes
+	st.GaitStartSteps = st.gaitSteps(st.AnimationMS)
+	st.GaitStartMS = st.AnimationMS
+	st.Gait = next
+	st.GaitSince = now
+}
+
+// gaitSteps returns the track steps taken by the animation clock ms
+// Sessions without a gait yet follow the stateless gallop from the epoch
+func (s *sessionState) gaitSteps(ms int64) float64 {
+	if s.Gait == "" {
+		return lookupGait(gaitGallop).stepsAt(time.Duration(ms) * time.Millisecond)
+	}
+	elapsed := time.Duration(ms-s.GaitStartMS) * time.Millisecond
+	return s.GaitStartSteps + lookupGait(s.Gait).stepsAt(elapsed)
+}
+
+// horseStateFor returns the horse state of a session at now
+// Stateless renders use the classic gallop driven by wall time
+func horseStateFor(s *sessionState, now time.Time, frameWidth int) horseState {
+	if s == nil || s.Gait == "" {
+		return horseStateAt(s.animationTime(now), frameWidth)
+	}
+	elapsed := time.Duration(s.animationTime(now).UnixMilli()-s.GaitStartMS) * time.Millisecond
+	return lookupGait(s.Gait).stateAt(s.Gait, elapsed, s.GaitStartSteps, frameWidth)
+}
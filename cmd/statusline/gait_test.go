@@ -0,0 +1,140 @@
+// Package main provides tests for the gait state machine
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGaitSequences_SpritesFitTheTrack(t *testing.T) {
+	for name, seq := range gaitSequences {
+		require.NotEmpty(t, seq.Frames, name)
+		require.NotEmpty(t, seq.Compact, name)
+		for i, frame := range seq.Frames {
+			assert.Len(t, frame, 2, "%s frame %d", name, i)
+			for _, line := range frame {
+				assert.LessOrEqual(t, StringWidth(line), MaxSpriteWidth(), "%s frame %d %q", name, i, line)
+			}
+		}
+		for i, sprite := range seq.Compact {
+			assert.Equal(t, StringWidth(HorseSpriteCompact[0]), StringWidth(sprite), "%s compact %d %q", name, i, sprite)
+		}
+		assert.Contains(t, gaitTransitions, name)
+	}
+}
+
+func TestHorseStateFor_StatelessIsClassicGallop(t *testing.T) {
+	for _, ms := range []int64{0, 250, 1_700_000_000_123} {
+		now := time.UnixMilli(ms)
+		state := horseStateFor(nil, now, 95)
+		assert.Equal(t, gaitGallop, state.Gait)
+		assert.Equal(t, int(ms/250)%NumFrames(), state.Frame)
+		assert.Equal(t, state.MaxPos-int(ms/500)%state.MaxPos, state.Position)
+	}
+}
+
+func TestTargetGait(t *testing.T) {
+	tests := []struct {
+		name string
+		in   gaitInputs
+		want string
+	}{
+		{"unmeasured", gaitInputs{}, gaitGallop},
+		{"stalled", gaitInputs{Measured: true, Throughput: 80, Stalled: time.Minute}, gaitRest},
+		{"slow", gaitInputs{Measured: true, Throughput: 2}, gaitWalk},
+		{"trot", gaitInputs{Measured: true, Throughput: 10}, gaitTrot},
+		{"canter", gaitInputs{Measured: true, Throughput: 30}, gaitCanter},
+		{"fast", gaitInputs{Measured: true, Throughput: 90}, gaitGallop},
+		{"alert wins", gaitInputs{Measured: true, Stalled: time.Minute, AlertRaised: true}, gaitRear},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, targetGait(tt.in))
+		})
+	}
+}
+
+func TestNextGait(t *testing.T) {
+	assert.Equal(t, gaitGallop, nextGait(gaitGallop, time.Second, gaitWalk), "held for MinHold")
+	assert.Equal(t, gaitCanter, nextGait(gaitGallop, 5*time.Second, gaitWalk), "one rung at a time")
+	assert.Equal(t, gaitTrot, nextGait(gaitCanter, 5*time.Second, gaitWalk))
+	assert.Equal(t, gaitRear, nextGait(gaitRest, 5*time.Second, gaitRear), "any gait may rear")
+	assert.Equal(t, gaitGallop, nextGait(gaitRear, 2*time.Second, gaitGallop), "rear returns straight to the target")
+	assert.Equal(t, gaitRear, nextGait(gaitRear, 500*time.Millisecond, gaitGallop), "rear plays out first")
+}
+
+func TestNextGait_RearCutsTheHoldShort(t *testing.T) {
+	// A new error alert usually arrives while a gait was just entered;
+	// the rear must not wait out the hold, or the alert is gone by then
+	for _, current := range []string{gaitWalk, gaitTrot, gaitCanter, gaitGallop} {
+		held := lookupGait(current).MinHold / 2
+		assert.Equal(t, gaitRear, nextGait(current, held, gaitRear), current)
+	}
+	assert.Equal(t, gaitGallop, nextGait(gaitGallop, 500*time.Millisecond, gaitWalk), "other targets still wait")
+}
+
+func TestGaitSequence_OnceHoldsLastFrame(t *testing.T) {
+	rear := gaitSequences[gaitRear]
+	assert.Equal(t, 1, rear.frameAt(300*time.Millisecond))
+	assert.Equal(t, len(rear.Frames)-1, rear.frameAt(time.Hour))
+	assert.Zero(t, rear.stepsAt(time.Hour), "rearing stays in place")
+}
+
+func TestAdvanceGait_SlowsDownAlongTheLadder(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	tokens := 0
+	var gaits []string
+	for i := 0; i < 12; i++ {
+		tokens += 2 // 1 tok/s: a crawl
+		updateSessionState(&st, testPayload{Output: tokens}.build(), t0.Add(time.Duration(i)*2*time.Second), nil)
+		if len(gaits) == 0 || gaits[len(gaits)-1] != st.Gait {
+			gaits = append(gaits, st.Gait)
+		}
+	}
+	assert.Equal(t, []string{gaitGallop, gaitCanter, gaitTrot, gaitWalk}, gaits)
+}
+
+func TestAdvanceGait_KeepsTrackPosition(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	updateSessionState(&st, testPayload{Output: 0}.build(), t0, nil)
+	require.Equal(t, gaitGallop, st.Gait)
+
+	now := t0.Add(3 * time.Second)
+	updateSessionState(&st, testPayload{Output: 3}.build(), now, nil) // 1 tok/s
+	require.Equal(t, gaitCanter, st.Gait)
+
+	// Where the gallop would have been on the same animation clock
+	clock := time.Duration(st.AnimationMS) * time.Millisecond
+	galloping := gaitSequences[gaitGallop].stateAt(gaitGallop, clock, 0, 95)
+	assert.Equal(t, galloping.Position, horseStateFor(&st, now, 95).Position, "changing gait does not move the horse")
+}
+
+func TestAdvanceGait_RearsOnNewErrorAlert(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	full := testPayload{Output: 0}.build()
+	full.ContextWindow.ContextWindowSize = 100
+	full.ContextWindow.CurrentUsage.InputTokens = 99
+
+	rules := defaultAlertRules()
+	var st sessionState
+	updateSessionState(&st, testPayload{Output: 0}.build(), t0, rules)
+	updateSessionState(&st, full, t0.Add(3*time.Second), rules)
+	assert.Equal(t, gaitRear, st.Gait)
+
+	updateSessionState(&st, full, t0.Add(6*time.Second), rules)
+	assert.NotEqual(t, gaitRear, st.Gait, "a standing alert does not rear again")
+}
+
+func TestParseArgs_Gait(t *testing.T) {
+	opts, err := parseArgs([]string{"--gait", "trot"})
+	require.NoError(t, err)
+	assert.Equal(t, gaitTrot, opts.Gait)
+
+	_, err = parseArgs([]string{"--gait=piaffe"})
+	assert.Error(t, err)
+}
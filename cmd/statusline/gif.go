@@ -0,0 +1,128 @@
+// Package main provides animated GIF export of the horse animation
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// gifBackground is the terminal-like background color of exported images
+var gifBackground = color.RGBA{0x1e, 0x1e, 0x1e, 0xff}
+
+// gifMaxFPS is the fastest rate GIF viewers honor: delays are in 1/100s
+// and most viewers replace delays below 2 with a slow default
+const gifMaxFPS = 50
+
+// gifOptions controls the exported animation
+type gifOptions struct {
+	Width    int           // Track width in terminal cells
+	Scale    int           // Integer pixel scale factor
+	FPS      int           // Frames per second
+	Duration time.Duration // Length of the animation
+	Start    time.Time     // Animation clock at the first frame
+	Theme    Theme
+}
+
+// animationCycle returns the time the horse needs for one lap of the track
+func animationCycle(trackWidth int) time.Duration {
+	return time.Duration(trackMaxPosition(trackWidth)) * 500 * time.Millisecond
+}
+
+// frameTimes returns the animation clock for every exported frame
+// Times are derived from start and fps only, so exports are reproducible
+func frameTimes(start time.Time, fps int, duration time.Duration) []time.Time {
+	count := int(duration * time.Duration(fps) / time.Second)
+	if count < 1 {
+		count = 1
+	}
+	times := make([]time.Time, count)
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * time.Second / time.Duration(fps))
+	}
+	return times
+}
+
+// exportGIF renders the frame/position cycle from getHorseLines into a GIF
+func exportGIF(w io.Writer, o gifOptions) error {
+	if o.Width <= 0 || o.Scale <= 0 || o.FPS <= 0 {
+		return fmt.Errorf("width, scale and fps must be positive")
+	}
+	if o.FPS > gifMaxFPS {
+		return fmt.Errorf("fps %d is too fast for gif, the maximum is %d", o.FPS, gifMaxFPS)
+	}
+
+	palette, index := gifPalette(o.Theme)
+	anim := &gif.GIF{}
+	delay := 100 / o.FPS // GIF delays are in 1/100s
+
+	for _, now := range frameTimes(o.Start, o.FPS, o.Duration) {
+		lines := getHorseLinesWidth(nil, now, o.Width)
+		styled := styleLines(lines, o.Theme)
+		anim.Image = append(anim.Image, rasterize(styled, o.Width, o.Scale, palette, index))
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// gifPalette builds the image palette for a theme
+// It returns the palette and a lookup from theme color to palette index
+func gifPalette(theme Theme) (color.Palette, map[int]uint8) {
+	palette := color.Palette{gifBackground}
+	index := map[int]uint8{}
+	for _, c := range []int{colorDefault, theme.Sprite, theme.Track} {
+		if _, ok := index[c]; ok {
+			continue
+		}
+		index[c] = uint8(len(palette))
+		palette = append(palette, xterm256RGB(c))
+	}
+	return palette, index
+}
+
+// rasterize draws styled lines with the built-in font, one cell per
+// terminal cell so wide characters take exactly two cells
+func rasterize(lines []styledLine, widthCells, scale int, palette color.Palette, index map[int]uint8) *image.Paletted {
+	bounds := image.Rect(0, 0,
+		widthCells*fontCellWidth*scale,
+		len(lines)*fontCellHeight*scale,
+	)
+	img := image.NewPaletted(bounds, palette)
+
+	for row, runs := range lines {
+		col := 0
+		for _, run := range runs {
+			ink := index[run.Color]
+			for _, ch := range run.Text {
+				cells := StringWidth(string(ch))
+				if cells == 0 {
+					continue
+				}
+				drawGlyph(img, glyphFor(ch, cells), col*fontCellWidth, row*fontCellHeight, scale, ink)
+				col += cells
+			}
+		}
+	}
+
+	return img
+}
+
+// drawGlyph paints a glyph bitmap at the given unscaled pixel origin
+func drawGlyph(img *image.Paletted, glyph []string, x0, y0, scale int, ink uint8) {
+	for y, row := range glyph {
+		for x := 0; x < len(row); x++ {
+			if row[x] != '#' {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x0+x)*scale+dx, (y0+y)*scale+dy, ink)
+				}
+			}
+		}
+	}
+}
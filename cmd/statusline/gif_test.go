@@ -0,0 +1,74 @@
+// Package main provides tests for GIF export and the bitmap font
+package main
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportGIF_FramesAndSize(t *testing.T) {
+	var buf bytes.Buffer
+	err := exportGIF(&buf, gifOptions{
+		Width:    40,
+		Scale:    2,
+		FPS:      4,
+		Duration: 2 * time.Second,
+		Start:    time.UnixMilli(0),
+		Theme:    defaultTheme,
+	})
+	require.NoError(t, err)
+
+	anim, err := gif.DecodeAll(&buf)
+	require.NoError(t, err)
+
+	// 2 seconds at 4 fps, each frame shown for 25/100s
+	assert.Len(t, anim.Image, 8)
+	assert.Equal(t, 25, anim.Delay[0])
+
+	bounds := anim.Image[0].Bounds()
+	assert.Equal(t, 40*fontCellWidth*2, bounds.Dx(), "width should follow terminal cells")
+	assert.Equal(t, 4*fontCellHeight*2, bounds.Dy(), "height should cover the four rows")
+}
+
+func TestExportGIF_RejectsFastFPS(t *testing.T) {
+	var buf bytes.Buffer
+	err := exportGIF(&buf, gifOptions{Width: 40, Scale: 1, FPS: 60, Duration: time.Second, Theme: defaultTheme})
+	assert.Error(t, err, "a delay below 2/100s plays at the viewer's default speed")
+}
+
+func TestExportGIF_Reproducible(t *testing.T) {
+	o := gifOptions{Width: 30, Scale: 1, FPS: 2, Duration: time.Second, Start: time.UnixMilli(0), Theme: defaultTheme}
+
+	var first, second bytes.Buffer
+	require.NoError(t, exportGIF(&first, o))
+	require.NoError(t, exportGIF(&second, o))
+	assert.Equal(t, first.Bytes(), second.Bytes())
+}
+
+func TestExportGIF_ThemeColors(t *testing.T) {
+	palette, index := gifPalette(Theme{Sprite: 21, Track: colorDefault})
+	assert.Equal(t, xterm256RGB(21), palette[index[21]], "sprite color should come from the theme")
+	assert.Len(t, palette, 3, "background, default and sprite colors")
+}
+
+func TestFontGlyphs_Dimensions(t *testing.T) {
+	for ch, glyph := range fontGlyphs {
+		assert.Len(t, glyph, fontCellHeight, "glyph %q height", ch)
+		cells := StringWidth(string(ch))
+		for i, row := range glyph {
+			assert.Equal(t, cells*fontCellWidth, len(row),
+				"glyph %q row %d should be %d cells wide", ch, i, cells)
+		}
+	}
+}
+
+func TestGlyphFor_Fallback(t *testing.T) {
+	glyph := glyphFor('Z', 1)
+	assert.Equal(t, boxGlyph(1), glyph, "unknown characters should render as a box")
+	assert.Len(t, glyphFor('漢', 2)[0], 2*fontCellWidth, "wide fallback spans two cells")
+}
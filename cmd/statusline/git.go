@@ -0,0 +1,128 @@
+// Package main provides lightweight git repository inspection
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitInfo describes the repository containing the working directory
+type gitInfo struct {
+	Root   string `json:"root"`
+	Branch string `json:"branch,omitempty"` // Empty when HEAD is detached
+	Commit string `json:"commit,omitempty"` // Full commit hash, if resolvable
+}
+
+// findGitInfo locates the repository containing dir by reading .git
+// directly, without spawning git, so it stays fast enough for every render
+func findGitInfo(dir string) *gitInfo {
+	if dir == "" {
+		return nil
+	}
+
+	for current := filepath.Clean(dir); ; {
+		gitDir, ok := resolveGitDir(filepath.Join(current, ".git"))
+		if ok {
+			info := &gitInfo{Root: current}
+			readGitHead(gitDir, info)
+			return info
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return nil
+		}
+		current = parent
+	}
+}
+
+// resolveGitDir returns the git directory for a .git entry
+// Worktrees and submodules use a .git file containing "gitdir: <path>"
+func resolveGitDir(dotGit string) (string, bool) {
+	fi, err := os.Stat(dotGit)
+	if err != nil {
+		return "", false
+	}
+	if fi.IsDir() {
+		return dotGit, true
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", false
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", false
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(dotGit), target)
+	}
+	return target, true
+}
+
+// readGitHead fills the branch and commit from HEAD
+func readGitHead(gitDir string, info *gitInfo) {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return
+	}
+	head := strings.TrimSpace(string(data))
+
+	ref, isRef := strings.CutPrefix(head, "ref: ")
+	if !isRef {
+		// Detached HEAD contains the commit hash itself
+		info.Commit = head
+		return
+	}
+
+	info.Branch = strings.TrimPrefix(ref, "refs/heads/")
+	info.Commit = resolveGitRef(gitDir, ref)
+}
+
+// resolveGitRef looks a ref up as a loose file, then in packed-refs
+// Worktrees keep shared refs in the common directory
+func resolveGitRef(gitDir, ref string) string {
+	dirs := []string{gitDir}
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		dirs = append(dirs, common)
+	}
+
+	for _, dir := range dirs {
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+		if commit := lookupPackedRef(filepath.Join(dir, "packed-refs"), ref); commit != "" {
+			return commit
+		}
+	}
+	return ""
+}
+
+// lookupPackedRef finds a ref in a packed-refs file
+func lookupPackedRef(path, ref string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+		if hash, name, ok := strings.Cut(line, " "); ok && name == ref {
+			return hash
+		}
+	}
+	return ""
+}
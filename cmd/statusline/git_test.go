@@ -0,0 +1,60 @@
+// Package main provides tests for git repository inspection
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile creates a file and its parent directories for a test
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFindGitInfo_BranchFromSubdirectory(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/feature/horse\n")
+	writeFile(t, filepath.Join(root, ".git", "refs", "heads", "feature", "horse"), "abc123\n")
+	sub := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+
+	info := findGitInfo(sub)
+	require.NotNil(t, info)
+	assert.Equal(t, root, info.Root)
+	assert.Equal(t, "feature/horse", info.Branch)
+	assert.Equal(t, "abc123", info.Commit)
+}
+
+func TestFindGitInfo_PackedRefs(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(root, ".git", "packed-refs"),
+		"# pack-refs with: peeled fully-peeled sorted\ndef456 refs/heads/main\n^fff000\n")
+
+	info := findGitInfo(root)
+	require.NotNil(t, info)
+	assert.Equal(t, "def456", info.Commit)
+}
+
+func TestFindGitInfo_DetachedWorktree(t *testing.T) {
+	root := t.TempDir()
+	gitDir := filepath.Join(root, "real-git-dir")
+	writeFile(t, filepath.Join(gitDir, "HEAD"), "0123456789abcdef\n")
+	writeFile(t, filepath.Join(root, "work", ".git"), "gitdir: ../real-git-dir\n")
+
+	info := findGitInfo(filepath.Join(root, "work"))
+	require.NotNil(t, info)
+	assert.Empty(t, info.Branch, "detached HEAD has no branch")
+	assert.Equal(t, "0123456789abcdef", info.Commit)
+}
+
+func TestFindGitInfo_NotARepository(t *testing.T) {
+	assert.Nil(t, findGitInfo(""))
+	assert.Nil(t, findGitInfo(filepath.Join(t.TempDir(), "missing")))
+}
@@ -0,0 +1,88 @@
+// Package main provides OSC 8 hyperlinks, which make the directory and
+// transcript segments clickable in terminals that support them
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Hyperlink modes
+const (
+	hyperlinksOff  = "off"  // Plain text
+	hyperlinksAuto = "auto" // Links when the terminal is known to support them
+	hyperlinksOn   = "on"   // Always link
+)
+
+// hyperlinkPrograms are TERM_PROGRAM values of terminals that support OSC 8
+var hyperlinkPrograms = []string{"iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby", "rio"}
+
+// hyperlinkTerms are TERM prefixes of terminals that support OSC 8
+var hyperlinkTerms = []string{"xterm-kitty", "xterm-ghostty", "foot", "alacritty", "wezterm"}
+
+// validateHyperlinks rejects an unknown hyperlink mode
+func validateHyperlinks(mode string) error {
+	switch mode {
+	case hyperlinksOff, hyperlinksAuto, hyperlinksOn:
+		return nil
+	}
+	return fmt.Errorf("invalid hyperlinks %q: want off, auto or on", mode)
+}
+
+// hyperlinksEnabled reports whether segments are linked in a mode
+func hyperlinksEnabled(mode string) bool {
+	switch mode {
+	case hyperlinksOn:
+		return true
+	case hyperlinksAuto:
+		return supportsHyperlinks(os.Getenv)
+	}
+	return false
+}
+
+// supportsHyperlinks detects a terminal that renders OSC 8 links from its
+// environment. Unknown terminals get plain text, since some print the
+// sequence verbatim
+func supportsHyperlinks(getenv func(string) string) bool {
+	term := getenv("TERM")
+	if term == "dumb" {
+		return false
+	}
+	for _, p := range hyperlinkPrograms {
+		if getenv("TERM_PROGRAM") == p {
+			return true
+		}
+	}
+	for _, prefix := range hyperlinkTerms {
+		if strings.HasPrefix(term, prefix) {
+			return true
+		}
+	}
+	if getenv("WT_SESSION") != "" || getenv("KITTY_WINDOW_ID") != "" ||
+		getenv("KONSOLE_VERSION") != "" || getenv("DOMTERM") != "" {
+		return true
+	}
+	// GNOME Terminal and other VTE terminals since 0.50
+	vte, err := strconv.Atoi(getenv("VTE_VERSION"))
+	return err == nil && vte >= 5000
+}
+
+// fileURI returns the file:// URI of a local path, with the hostname so
+// terminals can tell local files from remote ones
+func fileURI(path string) string {
+	host, _ := os.Hostname()
+	p := strings.ReplaceAll(path, "\\", "/")
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows drive paths: C:/x becomes /C:/x
+	}
+	u := url.URL{Scheme: "file", Host: host, Path: p}
+	return u.String()
+}
+
+// hyperlink wraps text in an OSC 8 link to uri
+func hyperlink(uri, text string) string {
+	return "\x1b]8;;" + uri + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
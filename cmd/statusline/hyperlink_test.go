@@ -0,0 +1,104 @@
+// Package main provides tests for OSC 8 hyperlinks
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportsHyperlinks(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "unknown terminal", env: map[string]string{"TERM": "xterm-256color"}, want: false},
+		{name: "iTerm2", env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: true},
+		{name: "kitty", env: map[string]string{"TERM": "xterm-kitty"}, want: true},
+		{name: "Windows Terminal", env: map[string]string{"WT_SESSION": "abc"}, want: true},
+		{name: "new VTE", env: map[string]string{"VTE_VERSION": "7200"}, want: true},
+		{name: "old VTE", env: map[string]string{"VTE_VERSION": "4800"}, want: false},
+		{name: "dumb", env: map[string]string{"TERM": "dumb", "WT_SESSION": "abc"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			assert.Equal(t, tt.want, supportsHyperlinks(getenv))
+		})
+	}
+}
+
+func TestFileURI(t *testing.T) {
+	host, _ := os.Hostname()
+	assert.Equal(t, "file://"+host+"/home/dev/my%20project", fileURI("/home/dev/my project"))
+	assert.Equal(t, "file://"+host+"/C:/Users/dev", fileURI(`C:\Users\dev`))
+	assert.NotContains(t, fileURI("/tmp/\x1b]0;x\a"), "\x1b", "control characters are escaped")
+}
+
+func TestAnsiLine_Hyperlink(t *testing.T) {
+	line := styledLine{{Text: "src", Color: colorDefault, Link: "file://host/src"}}
+	assert.Equal(t, "\x1b]8;;file://host/src\x1b\\src\x1b]8;;\x1b\\", ansiLine(line))
+	assert.Equal(t, 3, line.width(), "links do not count towards the width")
+}
+
+func TestBuildSegments_Hyperlinks(t *testing.T) {
+	input := &StatusLineInput{TranscriptPath: "/home/dev/.claude/projects/x/abc.jsonl"}
+	input.Workspace.CurrentDir = "/home/dev/project"
+
+	cfg := defaultConfig()
+	cfg.Segments = []string{"path", "transcript"}
+	segs := buildSegments(&segmentContext{Input: input, Config: cfg})
+	if assert.Len(t, segs, 2) {
+		assert.Empty(t, segs[0].Link, "plain text unless enabled")
+		assert.Equal(t, "📜 abc.jsonl", segs[1].Text)
+	}
+
+	cfg.Hyperlinks = hyperlinksOn
+	segs = buildSegments(&segmentContext{Input: input, Config: cfg})
+	assert.Equal(t, fileURI("/home/dev/project"), segs[0].Link)
+	assert.Equal(t, fileURI(input.TranscriptPath), segs[1].Link)
+
+	cfg.Path.Redact = []string{"project"}
+	segs = buildSegments(&segmentContext{Input: input, Config: cfg})
+	assert.Empty(t, segs[0].Link, "redacted paths are never linked")
+}
+
+func TestBuildSegments_RedactedPathsNotLinked(t *testing.T) {
+	input := &StatusLineInput{TranscriptPath: "/root/clients/acme/.claude/abc.jsonl"}
+	input.Workspace.ProjectDir = "/root/clients/acme"
+	input.Workspace.CurrentDir = "/root/clients/acme/src"
+
+	cfg := defaultConfig()
+	cfg.Segments = []string{"path", "transcript"}
+	cfg.Hyperlinks = hyperlinksOn
+	cfg.Path.Style = pathStyleFull
+	cfg.Path.Redact = []string{`^/root/clients/[^/]+`}
+	segs := buildSegments(&segmentContext{Input: input, Config: cfg})
+	if assert.Len(t, segs, 2) {
+		assert.NotContains(t, segs[0].Text, "acme")
+		assert.Empty(t, segs[0].Link, "the link would reveal the redacted client")
+		assert.Empty(t, segs[1].Link)
+	}
+
+	// A match the relative display cuts off is still in the link
+	cfg.Path.Style = pathStyleRelative
+	cfg.Path.Redact = []string{`^/root/clients`}
+	segs = buildSegments(&segmentContext{Input: input, Config: cfg})
+	if assert.Len(t, segs, 2) {
+		assert.Equal(t, "acme/src", segs[0].Text)
+		assert.Empty(t, segs[0].Link)
+	}
+
+	// Patterns see "/" separators on Windows too, as in the display
+	input.Workspace.ProjectDir = `C:\Users\dev\clients\acme`
+	input.Workspace.CurrentDir = `C:\Users\dev\clients\acme\src`
+	cfg.Path.Redact = []string{`/clients/[^/]+`}
+	segs = buildSegments(&segmentContext{Input: input, Config: cfg})
+	assert.Empty(t, segs[0].Link)
+
+	cfg.Path.Redact = []string{`/elsewhere/`}
+	segs = buildSegments(&segmentContext{Input: input, Config: cfg})
+	assert.Equal(t, fileURI(input.Workspace.CurrentDir), segs[0].Link, "no match, no change")
+}
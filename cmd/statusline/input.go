@@ -0,0 +1,193 @@
+// Package main provides bounded reading of the statusline payload
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Default limits for reading the payload
+const (
+	defaultInputMaxBytes  = 1 << 20 // 1 MiB, real payloads are a few KiB
+	defaultInputTimeoutMS = 2000
+)
+
+// inputConfig bounds how the payload is read
+type inputConfig struct {
+	MaxBytes  int64 `json:"max_bytes"`  // Size cap, extra bytes are discarded
+	TimeoutMS int64 `json:"timeout_ms"` // Read deadline in milliseconds
+}
+
+// defaultInputConfig returns the payload reading defaults
+func defaultInputConfig() inputConfig {
+	return inputConfig{MaxBytes: defaultInputMaxBytes, TimeoutMS: defaultInputTimeoutMS}
+}
+
+// timeout returns the read deadline as a duration
+func (c inputConfig) timeout() time.Duration {
+	return time.Duration(c.TimeoutMS) * time.Millisecond
+}
+
+// validate rejects limits that would make the plugin read nothing
+func (c inputConfig) validate() error {
+	if c.MaxBytes <= 0 || c.TimeoutMS <= 0 {
+		return fmt.Errorf("input max_bytes and timeout_ms must be positive")
+	}
+	return nil
+}
+
+// Reasons a payload read stopped early
+var (
+	errInputTooLarge = errors.New("input exceeds size limit")
+	errInputTimeout  = errors.New("input read timed out")
+)
+
+// openInput opens the payload source: the named file, or stdin when
+// path is empty. Closing the stdin reader is a no-op
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// readInput reads r until EOF, the size cap or the deadline, whichever
+// comes first. It always returns the bytes that arrived; the error tells
+// why reading stopped early. A caller that keeps stdin open can no longer
+// hang the plugin, and a huge payload is never buffered in full.
+func readInput(r io.Reader, maxBytes int64, timeout time.Duration) ([]byte, error) {
+	type chunk struct {
+		data []byte
+		err  error
+	}
+	chunks := make(chan chunk, 1)
+
+	// The reader goroutine may stay blocked in Read after a timeout;
+	// the process exits right after rendering, so it is never joined
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				chunks <- chunk{data: append([]byte(nil), buf[:n]...)}
+			}
+			if err != nil {
+				chunks <- chunk{err: err}
+				return
+			}
+		}
+	}()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var data []byte
+	for {
+		select {
+		case c := <-chunks:
+			if c.err != nil {
+				if errors.Is(c.err, io.EOF) {
+					return data, nil
+				}
+				return data, c.err
+			}
+			data = append(data, c.data...)
+			if int64(len(data)) > maxBytes {
+				return data[:maxBytes], errInputTooLarge
+			}
+		case <-deadline.C:
+			return data, errInputTimeout
+		}
+	}
+}
+
+// UnmarshalJSON decodes the payload tolerantly: unknown fields are kept in
+// Extra instead of being dropped. An unknown field nested in a modeled
+// object is kept under that object's key, so a new cost.new_field is
+// Extra["cost"] = {"new_field": ...}. As with encoding/json, a type
+// mismatch in one field does not prevent the others from decoding; the
+// first mismatch is still returned
+func (in *StatusLineInput) UnmarshalJSON(data []byte) error {
+	type plain StatusLineInput // Same fields without this method
+	typeErr := json.Unmarshal(data, (*plain)(in))
+	var syntaxErr *json.SyntaxError
+	if errors.As(typeErr, &syntaxErr) {
+		return typeErr
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		// Valid JSON that is not an object, e.g. an array
+		return err
+	}
+	in.Extra = unknownFields(reflect.TypeOf(StatusLineInput{}), raw)
+
+	return typeErr
+}
+
+// unknownFields returns the fields of a JSON object that struct type t
+// does not model, recursing into modeled struct fields. It returns nil
+// when every field is known
+func unknownFields(t reflect.Type, raw map[string]json.RawMessage) map[string]json.RawMessage {
+	known := modeledFields(t)
+	var extra map[string]json.RawMessage
+	keep := func(key string, value json.RawMessage) {
+		if extra == nil {
+			extra = map[string]json.RawMessage{}
+		}
+		extra[key] = value
+	}
+	for key, value := range raw {
+		field, ok := known[key]
+		if !ok {
+			keep(key, value)
+			continue
+		}
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(value, &nested) != nil {
+			continue // Not an object, already reported as a type mismatch
+		}
+		if inner := unknownFields(field, nested); inner != nil {
+			if b, err := json.Marshal(inner); err == nil {
+				keep(key, b)
+			}
+		}
+	}
+	return extra
+}
+
+// modeledFields returns the JSON keys a struct type models and their types
+func modeledFields(t reflect.Type) map[string]reflect.Type {
+	known := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			known[name] = t.Field(i).Type
+		}
+	}
+	return known
+}
+
+// extraValues decodes the unknown payload fields for templates
+func (in *StatusLineInput) extraValues() map[string]any {
+	values := map[string]any{}
+	if in == nil {
+		return values
+	}
+	for key, raw := range in.Extra {
+		var v any
+		if json.Unmarshal(raw, &v) == nil {
+			values[key] = v
+		}
+	}
+	return values
+}
@@ -0,0 +1,152 @@
+// Package main provides tests for bounded payload reading
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadInput_Complete(t *testing.T) {
+	data, err := readInput(strings.NewReader(`{"cwd":"/tmp"}`), 1024, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, `{"cwd":"/tmp"}`, string(data))
+}
+
+func TestReadInput_SizeCap(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 100*1024)
+
+	data, err := readInput(bytes.NewReader(payload), 1000, time.Second)
+	assert.ErrorIs(t, err, errInputTooLarge)
+	assert.Len(t, data, 1000, "the bytes up to the cap are kept")
+}
+
+func TestReadInput_TimeoutKeepsPartialData(t *testing.T) {
+	// The writer sends part of a payload and then keeps stdin open
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte(`{"model":`))
+
+	start := time.Now()
+	data, err := readInput(r, 1024, 100*time.Millisecond)
+
+	assert.ErrorIs(t, err, errInputTimeout)
+	assert.Equal(t, `{"model":`, string(data))
+	assert.Less(t, time.Since(start), time.Second, "the deadline must not be exceeded by much")
+}
+
+func TestReadInput_ReadError(t *testing.T) {
+	broken := errors.New("broken pipe")
+	r := io.MultiReader(strings.NewReader("abc"), iotestErrReader{err: broken})
+
+	data, err := readInput(r, 1024, time.Second)
+	assert.ErrorIs(t, err, broken)
+	assert.Equal(t, "abc", string(data))
+}
+
+// iotestErrReader always fails with err
+type iotestErrReader struct{ err error }
+
+func (r iotestErrReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestInputConfig_Validate(t *testing.T) {
+	assert.NoError(t, defaultInputConfig().validate())
+	assert.Error(t, inputConfig{MaxBytes: 0, TimeoutMS: 10}.validate())
+	assert.Error(t, inputConfig{MaxBytes: 10, TimeoutMS: 0}.validate())
+}
+
+// fullPayload is a statusline payload with every field Claude Code sends
+const fullPayload = `{
+	"hook_event_name": "Status",
+	"session_id": "abc123",
+	"transcript_path": "/tmp/abc123.jsonl",
+	"cwd": "/home/me/app",
+	"model": {"id": "claude-opus", "display_name": "Opus"},
+	"workspace": {"current_dir": "/home/me/app", "project_dir": "/home/me/app"},
+	"version": "1.0.80",
+	"output_style": {"name": "Explanatory"},
+	"cost": {
+		"total_cost_usd": 0.42,
+		"total_duration_ms": 45000,
+		"total_api_duration_ms": 2300,
+		"total_lines_added": 156,
+		"total_lines_removed": 23
+	},
+	"exceeds_200k_tokens": true,
+	"context_window": {
+		"context_window_size": 200000,
+		"current_usage": {"input_tokens": 10, "cache_creation_input_tokens": 90}
+	},
+	"future_field": {"nested": [1, 2]},
+	"agent": "reviewer"
+}`
+
+func TestStatusLineInput_ExtendedSchema(t *testing.T) {
+	var input StatusLineInput
+	require.NoError(t, json.Unmarshal([]byte(fullPayload), &input))
+
+	assert.Equal(t, "Status", input.HookEventName)
+	assert.Equal(t, "abc123", input.SessionID)
+	assert.Equal(t, "1.0.80", input.Version)
+	assert.Equal(t, "Explanatory", input.OutputStyle.Name)
+	assert.Equal(t, int64(45000), input.Cost.TotalDurationMS)
+	assert.Equal(t, int64(2300), input.Cost.TotalAPIDurationMS)
+	assert.Equal(t, 156, input.Cost.TotalLinesAdded)
+	assert.Equal(t, 23, input.Cost.TotalLinesRemoved)
+	assert.True(t, input.Exceeds200kTokens)
+	assert.Equal(t, 100, contextUsage(&input).UsedTokens, "cache_creation_input_tokens counts as context")
+}
+
+func TestStatusLineInput_UnknownFieldsKept(t *testing.T) {
+	var input StatusLineInput
+	require.NoError(t, json.Unmarshal([]byte(fullPayload), &input))
+
+	assert.Len(t, input.Extra, 2)
+	assert.JSONEq(t, `{"nested": [1, 2]}`, string(input.Extra["future_field"]))
+	assert.Equal(t, "reviewer", input.extraValues()["agent"])
+}
+
+func TestStatusLineInput_NestedUnknownFieldsKept(t *testing.T) {
+	var input StatusLineInput
+	payload := `{
+		"cost": {"total_cost_usd": 1.5, "new_field": 7},
+		"context_window": {"current_usage": {"input_tokens": 10, "cache_hint": "warm"}},
+		"workspace": {"current_dir": "/tmp"},
+		"model": {"id": "m", "tier": "max"}
+	}`
+	require.NoError(t, json.Unmarshal([]byte(payload), &input))
+
+	assert.Equal(t, 1.5, input.Cost.TotalCostUSD)
+	assert.JSONEq(t, `{"new_field": 7}`, string(input.Extra["cost"]))
+	assert.JSONEq(t, `{"current_usage": {"cache_hint": "warm"}}`, string(input.Extra["context_window"]))
+	assert.JSONEq(t, `{"tier": "max"}`, string(input.Extra["model"]))
+	assert.NotContains(t, input.Extra, "workspace", "fully modeled objects add nothing")
+
+	cost, _ := input.extraValues()["cost"].(map[string]any)
+	assert.Equal(t, float64(7), cost["new_field"])
+}
+
+func TestStatusLineInput_TolerantTypes(t *testing.T) {
+	var input StatusLineInput
+	err := json.Unmarshal([]byte(`{"session_id": 42, "cwd": "/tmp", "new": true}`), &input)
+
+	var typeErr *json.UnmarshalTypeError
+	assert.ErrorAs(t, err, &typeErr, "the mismatch is still reported")
+	assert.Equal(t, "/tmp", input.Cwd, "other fields still decode")
+	assert.Contains(t, input.Extra, "new")
+}
+
+func TestStatusLineInput_SyntaxError(t *testing.T) {
+	var input StatusLineInput
+	err := json.Unmarshal([]byte(`{"cwd": `), &input)
+
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+}
@@ -0,0 +1,384 @@
+// Package main provides "statusline inspect", which explains how a payload
+// was parsed so empty segments can be debugged after a Claude Code update
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// inspectIssue is one problem found in the payload
+type inspectIssue struct {
+	Offset  int64  // Byte offset of the offending value or key
+	Path    string // Dotted field path, e.g. model.display_name
+	Message string
+}
+
+// inspectReport is everything inspect found out about a payload
+type inspectReport struct {
+	Size       int
+	ReadErr    error         // Why reading stopped early, if it did
+	Syntax     *inspectIssue // The payload is not valid JSON
+	Mismatches []inspectIssue
+	Unknown    []inspectIssue
+	Missing    []string // Modeled fields absent from the payload
+	Input      StatusLineInput
+}
+
+// inspectPayload checks data against StatusLineInput field by field
+// Unlike json.Unmarshal, which stops at the first type mismatch, every
+// mismatch and unknown field is collected with its offset
+func inspectPayload(data []byte) inspectReport {
+	report := inspectReport{Size: len(data)}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		var syntaxErr *json.SyntaxError
+		offset := int64(len(data))
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+			if strings.HasPrefix(err.Error(), "invalid character") {
+				offset-- // The offset counts the offending byte
+			}
+		}
+		report.Syntax = &inspectIssue{Offset: offset, Message: err.Error()}
+		return report
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	w := &payloadWalker{data: data, dec: dec, report: &report}
+	_ = w.value(reflect.TypeOf(StatusLineInput{}), "")
+	sort.Strings(report.Missing)
+
+	// Type mismatches are already listed; decode what can be decoded
+	_ = json.Unmarshal(data, &report.Input)
+	return report
+}
+
+// payloadWalker walks the JSON token stream alongside a Go type
+type payloadWalker struct {
+	data   []byte
+	dec    *json.Decoder
+	report *inspectReport
+}
+
+// value reads one JSON value that should decode into t
+func (w *payloadWalker) value(t reflect.Type, path string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	start := w.start()
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil // null leaves the zero value, as encoding/json does
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && tok == json.Delim('{'):
+		return w.object(t, path)
+	case t.Kind() == reflect.Map && tok == json.Delim('{'):
+		for w.dec.More() {
+			key, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := w.value(t.Elem(), joinFieldPath(path, fmt.Sprint(key))); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token()
+		return err
+	case t.Kind() == reflect.Slice && tok == json.Delim('['):
+		for i := 0; w.dec.More(); i++ {
+			if err := w.value(t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token()
+		return err
+	case tokenFits(tok, t):
+		return w.skip(tok)
+	}
+
+	if path == "" {
+		path = "(root)"
+	}
+	w.report.Mismatches = append(w.report.Mismatches, inspectIssue{
+		Offset:  start,
+		Path:    path,
+		Message: fmt.Sprintf("got %s, want %s", tokenKind(tok), typeKind(t)),
+	})
+	return w.skip(tok)
+}
+
+// object reads the members of a JSON object that should decode into t
+func (w *payloadWalker) object(t reflect.Type, path string) error {
+	fields := jsonFields(t)
+	seen := map[string]bool{}
+	for w.dec.More() {
+		start := w.start()
+		tok, err := w.dec.Token()
+		if err != nil {
+			return err
+		}
+		key := fmt.Sprint(tok)
+		field, ok := findField(fields, key)
+		seen[field.Name] = ok
+		if !ok {
+			w.report.Unknown = append(w.report.Unknown, inspectIssue{Offset: start, Path: joinFieldPath(path, key)})
+			tok, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := w.skip(tok); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.value(field.Type, joinFieldPath(path, key)); err != nil {
+			return err
+		}
+	}
+	if _, err := w.dec.Token(); err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if !seen[f.Name] {
+			w.report.Missing = append(w.report.Missing, joinFieldPath(path, f.Name))
+		}
+	}
+	return nil
+}
+
+// skip consumes the rest of a value whose first token was tok
+func (w *payloadWalker) skip(tok json.Token) error {
+	if tok != json.Delim('{') && tok != json.Delim('[') {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		tok, err := w.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// start returns the offset of the next token; the decoder offset points
+// just past the previous token, before any separator and whitespace
+func (w *payloadWalker) start() int64 {
+	off := w.dec.InputOffset()
+	for off < int64(len(w.data)) && strings.IndexByte(" \t\r\n:,", w.data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+// jsonField is a struct field as seen by encoding/json
+type jsonField struct {
+	Name string
+	Type reflect.Type
+}
+
+// jsonFields returns the JSON-visible fields of a struct in declaration order
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{Name: name, Type: f.Type})
+	}
+	return fields
+}
+
+// findField matches a key like encoding/json: exact first, then case-insensitive
+func findField(fields []jsonField, key string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.Name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, key) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// tokenFits reports whether a scalar token decodes into t
+func tokenFits(tok json.Token, t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.String:
+		_, ok := tok.(string)
+		return ok
+	case reflect.Bool:
+		_, ok := tok.(bool)
+		return ok
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := tok.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseInt(string(n), 10, t.Bits())
+		return err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := tok.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseUint(string(n), 10, t.Bits())
+		return err == nil
+	case reflect.Float32, reflect.Float64:
+		_, ok := tok.(json.Number)
+		return ok
+	}
+	return false
+}
+
+// tokenKind names the JSON type of the value starting with tok
+func tokenKind(tok json.Token) string {
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '[' {
+			return "array"
+		}
+		return "object"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return "number " + string(v)
+		}
+		return "integer " + string(v)
+	}
+	return "null"
+}
+
+// typeKind names the JSON type a Go type decodes from
+func typeKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "non-negative integer"
+	}
+	return "integer"
+}
+
+// joinFieldPath appends a key to a dotted field path
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// lineCol converts a byte offset into a 1-based line and column
+func lineCol(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len([]rune(string(before[bytes.LastIndexByte(before, '\n')+1:]))) + 1
+	return line, col
+}
+
+// writeInspectReport prints the report, the normalized input and the
+// text of every configured segment, so an empty segment can be traced
+// back to the field it reads
+func writeInspectReport(out io.Writer, data []byte, r inspectReport, cfg Config, now time.Time) {
+	fmt.Fprintf(out, "payload: %d bytes\n", r.Size)
+	if r.ReadErr != nil {
+		fmt.Fprintf(out, "read: %v\n", r.ReadErr)
+	}
+
+	pos := func(offset int64) string {
+		line, col := lineCol(data, offset)
+		return fmt.Sprintf("%d:%d", line, col)
+	}
+	if r.Syntax != nil {
+		fmt.Fprintf(out, "\nsyntax error at %s (offset %d): %s\n", pos(r.Syntax.Offset), r.Syntax.Offset, r.Syntax.Message)
+		return
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	section := func(title string, issues []inspectIssue) {
+		fmt.Fprintf(tw, "\n%s: %d\n", title, len(issues))
+		for _, is := range issues {
+			// Paths are built from payload keys, which may hold escape sequences
+			path, message := sanitizeText(is.Path), sanitizeText(is.Message)
+			if message == "" {
+				fmt.Fprintf(tw, "  %s\t%s\n", pos(is.Offset), path)
+				continue
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", pos(is.Offset), path, message)
+		}
+	}
+	section("type mismatches", r.Mismatches)
+	section("unknown fields", r.Unknown)
+	fmt.Fprintf(tw, "\nmissing fields (left at zero value): %d\n", len(r.Missing))
+	for _, path := range r.Missing {
+		fmt.Fprintf(tw, "  %s\n", path)
+	}
+	tw.Flush()
+
+	normalized, _ := json.MarshalIndent(r.Input, "", "  ")
+	fmt.Fprintf(out, "\nnormalized input:\n%s\n", normalized)
+
+	// Segment builders are run one by one; buildSegments drops empty ones
+	input := &r.Input
+	ctx := &segmentContext{Input: input, Status: buildStatusModel(input, horseState{}, nil, cfg.Alerts.Rules), Config: cfg, Now: now}
+	fmt.Fprintf(tw, "\nsegments:\n")
+	for _, name := range cfg.Segments {
+		build, ok := lookupSegment(name, cfg)
+		if !ok {
+			continue
+		}
+		text := sanitizeText(safeSegment(name, build, ctx).Text)
+		if text == "" {
+			text = "(empty)"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", name, text)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,104 @@
+// Package main provides tests for the "statusline inspect" subcommand
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectPayload_SyntaxErrorPosition(t *testing.T) {
+	data := []byte("{\"cwd\":\n \"x\",}")
+	r := inspectPayload(data)
+
+	require.NotNil(t, r.Syntax)
+	line, col := lineCol(data, r.Syntax.Offset)
+	assert.Equal(t, 2, line)
+	assert.Equal(t, 6, col, "points at the offending brace")
+}
+
+func TestInspectPayload_AllMismatches(t *testing.T) {
+	data := []byte(`{"session_id": 42, "model": {"display_name": ["Opus"]}, "cost": {"total_lines_added": 1.5}, "cwd": "/tmp"}`)
+	r := inspectPayload(data)
+
+	require.Nil(t, r.Syntax)
+	var paths []string
+	for _, m := range r.Mismatches {
+		paths = append(paths, m.Path)
+	}
+	assert.Equal(t, []string{"session_id", "model.display_name", "cost.total_lines_added"}, paths)
+	assert.Equal(t, "got integer 42, want string", r.Mismatches[0].Message)
+	assert.Equal(t, int64(15), r.Mismatches[0].Offset)
+	assert.Equal(t, "/tmp", r.Input.Cwd, "valid fields are still decoded")
+}
+
+func TestInspectPayload_UnknownAndMissing(t *testing.T) {
+	data := []byte(`{"model": {"id": "opus", "tier": "max"}, "agent": {"name": "x"}, "CWD": "/tmp"}`)
+	r := inspectPayload(data)
+
+	require.Len(t, r.Unknown, 2)
+	assert.Equal(t, "model.tier", r.Unknown[0].Path)
+	assert.Equal(t, "agent", r.Unknown[1].Path)
+
+	assert.Contains(t, r.Missing, "model.display_name")
+	assert.Contains(t, r.Missing, "session_id")
+	assert.NotContains(t, r.Missing, "cwd", "keys match case-insensitively like encoding/json")
+	assert.NotContains(t, r.Missing, "model")
+}
+
+func TestInspectPayload_NotAnObject(t *testing.T) {
+	r := inspectPayload([]byte(`[1, 2]`))
+
+	require.Len(t, r.Mismatches, 1)
+	assert.Equal(t, "(root)", r.Mismatches[0].Path)
+	assert.Equal(t, "got array, want object", r.Mismatches[0].Message)
+}
+
+func TestLineCol(t *testing.T) {
+	data := []byte("ab\ncdé\nf")
+	tests := []struct {
+		offset    int64
+		line, col int
+	}{
+		{0, 1, 1},
+		{3, 2, 1},
+		{7, 2, 4}, // é is two bytes but one column
+		{100, 3, 2},
+	}
+	for _, tt := range tests {
+		line, col := lineCol(data, tt.offset)
+		assert.Equal(t, tt.line, line, "offset %d", tt.offset)
+		assert.Equal(t, tt.col, col, "offset %d", tt.offset)
+	}
+}
+
+func TestWriteInspectReport(t *testing.T) {
+	data := []byte(`{"model": {"display_name": "Opus"}, "agent": "x"}`)
+	cfg := defaultConfig()
+	cfg.Segments = []string{"model", "cost"}
+
+	var buf bytes.Buffer
+	writeInspectReport(&buf, data, inspectPayload(data), cfg, time.UnixMilli(0))
+	out := buf.String()
+
+	assert.Contains(t, out, "unknown fields: 1")
+	assert.Contains(t, out, "1:37  agent")
+	assert.Contains(t, out, `"display_name": "Opus"`)
+	assert.Contains(t, out, "model  Opus")
+	assert.Contains(t, out, "cost   (empty)")
+}
+
+func TestWriteInspectReport_SanitizesKeys(t *testing.T) {
+	data := []byte(`{"\u001b]0;pwned\u0007": 1, "model": {"id": 5}}`)
+
+	var buf bytes.Buffer
+	writeInspectReport(&buf, data, inspectPayload(data), defaultConfig(), time.UnixMilli(0))
+	out := buf.String()
+
+	assert.Contains(t, out, "unknown fields: 1")
+	assert.NotContains(t, out, "\x1b", "payload keys never reach the terminal as escapes")
+	assert.NotContains(t, out, "\a")
+}
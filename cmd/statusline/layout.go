@@ -0,0 +1,86 @@
+// Package main provides the full and compact status line layouts
+package main
+
+import (
+	"strings"
+)
+
+// Terminals smaller than this get the compact layout in auto mode
+const (
+	autoCompactMinCols = 95
+	autoCompactMinRows = 15
+)
+
+// Compact track width bounds in cells
+const (
+	compactTrackWidth    = 40 // Used when the terminal width is unknown
+	compactMinTrackWidth = 12
+)
+
+// statusFrame is one rendered status: the horse rows plus segments
+type statusFrame struct {
+	Horse    []styledLine
+	Segments styledLine
+	Compact  bool
+}
+
+// lines returns the output rows of the frame
+// The full layout puts segments on their own row below the horse;
+// the compact layout appends them to the single horse row
+func (f statusFrame) lines() []styledLine {
+	if len(f.Segments) == 0 {
+		return f.Horse
+	}
+	if f.Compact && len(f.Horse) == 1 {
+		row := append(styledLine{}, f.Horse[0]...)
+		row = append(row, styledRun{Text: " ", Color: colorDefault})
+		return []styledLine{append(row, f.Segments...)}
+	}
+	return append(append([]styledLine{}, f.Horse...), f.Segments)
+}
+
+// useCompactLayout decides between the full and compact layouts
+// In auto mode the compact layout is used when the terminal is known to be
+// narrower than the full track or too short for the four-row horse
+func useCompactLayout(layout string, cols, rows int) bool {
+	switch layout {
+	case layoutCompact:
+		return true
+	case layoutFull:
+		return false
+	}
+	if cols > 0 && cols < autoCompactMinCols {
+		return true
+	}
+	return rows > 0 && rows < autoCompactMinRows
+}
+
+// compactWidth returns the track width that fits the terminal next to
+// segments of the given width
+func compactWidth(cols, segmentsWidth int) int {
+	if cols <= 0 {
+		return compactTrackWidth
+	}
+	width := cols - segmentsWidth - 1
+	if full := defaultTrackWidth(); width > full {
+		width = full
+	}
+	if width < compactMinTrackWidth {
+		width = compactMinTrackWidth
+	}
+	return width
+}
+
+// drawCompactHorse builds the single-row track with the one-row sprite
+func drawCompactHorse(state horseState, frameWidth int) []string {
+	sprite := gaitCompactSprite(state.Gait, state.Frame)
+
+	var row strings.Builder
+	row.WriteString(strings.Repeat(".", state.Position))
+	row.WriteString(sprite)
+	if remaining := frameWidth - state.Position - StringWidth(sprite); remaining > 0 {
+		row.WriteString(strings.Repeat(".", remaining))
+	}
+
+	return []string{TruncateWidth(row.String(), frameWidth)}
+}
@@ -0,0 +1,64 @@
+// Package main provides tests for the full and compact layouts
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseCompactLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   string
+		cols     int
+		rows     int
+		expected bool
+	}{
+		{name: "forced compact", layout: layoutCompact, cols: 200, rows: 50, expected: true},
+		{name: "forced full", layout: layoutFull, cols: 20, rows: 5, expected: false},
+		{name: "auto unknown size", layout: layoutAuto, expected: false},
+		{name: "auto wide and tall", layout: layoutAuto, cols: 200, rows: 50, expected: false},
+		{name: "auto narrow", layout: layoutAuto, cols: 80, rows: 50, expected: true},
+		{name: "auto short", layout: layoutAuto, cols: 200, rows: 10, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, useCompactLayout(tt.layout, tt.cols, tt.rows))
+		})
+	}
+}
+
+func TestCompactWidth(t *testing.T) {
+	assert.Equal(t, compactTrackWidth, compactWidth(0, 30), "unknown terminal width")
+	assert.Equal(t, 49, compactWidth(80, 30), "fits next to the segments")
+	assert.Equal(t, 95, compactWidth(300, 30), "never wider than the full track")
+	assert.Equal(t, compactMinTrackWidth, compactWidth(20, 30), "never narrower than the minimum")
+}
+
+func TestDrawCompactHorse_SingleRow(t *testing.T) {
+	for ms := int64(0); ms < 30000; ms += 250 {
+		state := horseStateAt(time.UnixMilli(ms), 40)
+		lines := drawCompactHorse(state, 40)
+		assert.Len(t, lines, 1)
+		assert.Equal(t, 40, StringWidth(lines[0]), "at %dms: %q", ms, lines[0])
+		assert.Contains(t, lines[0], "🐴")
+	}
+}
+
+func TestStatusFrameLines(t *testing.T) {
+	horse := styleLines([]string{"..", "🐴", "ﾉﾉ", ".."}, defaultTheme)
+	segs := segmentLine([]segment{{Text: "Opus", Color: colorDefault}}, defaultTheme)
+
+	full := statusFrame{Horse: horse, Segments: segs}
+	assert.Len(t, full.lines(), 5, "full layout puts segments on their own row")
+
+	compact := statusFrame{Horse: styleLines([]string{"..🐴ﾉﾉ~.."}, defaultTheme), Segments: segs, Compact: true}
+	lines := compact.lines()
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "..🐴ﾉﾉ~.. Opus", lines[0].plain())
+
+	assert.Len(t, statusFrame{Horse: horse}.lines(), 4, "no segments, no extra row")
+}
@@ -2,36 +2,46 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"strings"
 	"time"
 )
 
 // StatusLineInput represents the JSON input from Claude Code
 type StatusLineInput struct {
-	Model struct {
+	HookEventName string `json:"hook_event_name"`
+	SessionID     string `json:"session_id"`
+	Version       string `json:"version"` // Claude Code version
+	Model         struct {
 		DisplayName string `json:"display_name"`
 		ID          string `json:"id"`
 	} `json:"model"`
+	OutputStyle struct {
+		Name string `json:"name"`
+	} `json:"output_style"`
 	ContextWindow struct {
 		TotalInputTokens  int `json:"total_input_tokens"`
 		TotalOutputTokens int `json:"total_output_tokens"`
 		ContextWindowSize int `json:"context_window_size"`
 		CurrentUsage      struct {
-			InputTokens          int `json:"input_tokens"`
-			OutputTokens         int `json:"output_tokens"`
-			CacheReadInputTokens int `json:"cache_read_input_tokens"`
-			CacheCreationTokens  int `json:"cache_creation_tokens"`
+			InputTokens              int `json:"input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+			CacheCreationTokens      int `json:"cache_creation_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
 		} `json:"current_usage"`
 	} `json:"context_window"`
-	TranscriptPath string `json:"transcript_path"`
-	Cwd            string `json:"cwd"`
-	Workspace      struct {
+	Exceeds200kTokens bool   `json:"exceeds_200k_tokens"`
+	TranscriptPath    string `json:"transcript_path"`
+	Cwd               string `json:"cwd"`
+	Workspace         struct {
 		CurrentDir string `json:"current_dir"`
 		ProjectDir string `json:"project_dir"`
 	} `json:"workspace"`
@@ -39,33 +49,39 @@ type StatusLineInput struct {
 		Remaining int `json:"remaining"`
 		Limit     int `json:"limit"`
 	} `json:"rate_limit"`
+	Cost struct {
+		TotalCostUSD       float64 `json:"total_cost_usd"`
+		TotalDurationMS    int64   `json:"total_duration_ms"`
+		TotalAPIDurationMS int64   `json:"total_api_duration_ms"`
+		TotalLinesAdded    int     `json:"total_lines_added"`
+		TotalLinesRemoved  int     `json:"total_lines_removed"`
+	} `json:"cost"`
+
+	// Extra keeps fields this version does not model yet, nested ones under
+	// their parent key, so templates and custom segments can use them
+	// without a plugin update
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 func main() {
 	// Parse command line flags
-	args := os.Args[1:]
-	animateMode := false
-	debugMode := false
-	for _, arg := range args {
-		if arg == "--version" || arg == "-v" {
-			fmt.Println("claude-ride-with-whip statusline v0.1.0")
-			os.Exit(0)
-		}
-		if arg == "--help" || arg == "-h" {
-			printHelp()
-			os.Exit(0)
-		}
-		if arg == "--animate" || arg == "-a" {
-			animateMode = true
-		}
-		if arg == "--debug" || arg == "-d" {
-			debugMode = true
-		}
+	opts, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "statusline:", err)
+		os.Exit(2)
+	}
+	if opts.ShowVersion {
+		fmt.Println("claude-ride-with-whip statusline v0.1.0")
+		os.Exit(0)
+	}
+	if opts.ShowHelp {
+		printHelp()
+		os.Exit(0)
 	}
 
 	// Get debug log file path
 	var debugFile *os.File
-	if debugMode {
+	if opts.Debug {
 		debugFilePath := getDebugFilePath()
 		var err error
 		debugFile, err = os.OpenFile(debugFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
@@ -85,33 +101,118 @@ func main() {
 		initConsole()
 	}
 
+	// Load the config file; a broken config must never break the statusline
+	cfg, err := loadConfig(opts.ConfigPath)
+	if err != nil {
+		debugLog(debugFile, "%v", err)
+	}
+	if opts.Layout != "" {
+		cfg.Layout = opts.Layout
+	}
+	if opts.Record {
+		cfg.Record.Enabled = true
+	}
+	if opts.Progress != "" {
+		cfg.Progress = opts.Progress
+	}
+	if opts.Title {
+		cfg.Title.Enabled = true
+	}
+
+	// Subcommands: export, tmux, inspect, replay, simulate
+	if len(opts.Args) > 0 {
+		if err := runCommand(opts, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, "statusline:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Animation mode: continuous animation in terminal
-	if animateMode {
+	if opts.Animate {
 		runAnimationMode()
 		return
 	}
 
-	// Read all input from stdin
-	inputBytes, err := io.ReadAll(os.Stdin)
+	// Read the payload from stdin, or from --input for reproducible renders,
+	// bounded by the configured size cap and read deadline
+	source, err := openInput(opts.InputPath)
 	if err != nil {
-		// Silent fail for invalid input
-		os.Exit(0)
+		fmt.Fprintln(os.Stderr, "statusline:", err)
+		os.Exit(1)
+	}
+	defer source.Close()
+	inputBytes, readErr := readInput(source, cfg.Input.MaxBytes, cfg.Input.timeout())
+	if readErr != nil {
+		// Render with whatever data arrived
+		debugLog(debugFile, "input: %v after %d bytes", readErr, len(inputBytes))
+	}
+
+	// Capture the raw payload for "statusline replay", odd bytes included
+	if cfg.Record.Enabled && opts.InputPath == "" {
+		if err := recordPayload(cfg.Record, time.Now(), inputBytes); err != nil {
+			debugLog(debugFile, "record: %v", err)
+		}
 	}
 
 	// Trim null bytes
 	inputBytes = trimNullBytes(inputBytes)
 	if len(inputBytes) == 0 {
 		// No input, still render the horse
-		renderStatusLineMulti(nil, debugFile)
+		renderStatusLineMulti(nil, nil, debugFile, opts, cfg)
 		return
 	}
 
+	// Keep the payload for "statusline tmux", which runs without stdin
+	if opts.InputPath == "" && readErr == nil {
+		_ = cacheLastInput(inputBytes)
+	}
+
 	// Try to parse JSON (optional for this plugin)
 	var input StatusLineInput
-	_ = json.Unmarshal(inputBytes, &input)
+	if err := json.Unmarshal(inputBytes, &input); err != nil {
+		// "statusline inspect" explains the error in detail
+		debugLog(debugFile, "input: %v", err)
+		if readErr != nil {
+			// Cut off payloads rarely parse, fall back to the bare horse
+			renderStatusLineMulti(nil, nil, debugFile, opts, cfg)
+			return
+		}
+	}
+
+	// Per-session state is only advanced by real calls from Claude Code,
+	// never by reproductions (--input, --at, --frame, --position, --gait)
+	// or cut off payloads. A panic while updating it renders the call
+	// without a session
+	var session *sessionState
+	if opts.InputPath == "" && readErr == nil && !opts.overridesRender() {
+		safeCall(debugFile, "session", func() {
+			session = recordSession(&input, time.Now(), cfg, debugFile)
+		})
+		if session != nil {
+			debugLog(debugFile, "session %s call %d, %v since last", session.Key, session.Calls, session.idle())
+		}
+	}
 
 	// Render status line (multi-line output) - always show the horse
-	renderStatusLineMulti(&input, debugFile)
+	renderStatusLineMulti(&input, session, debugFile, opts, cfg)
+	safeCall(debugFile, "tty", func() {
+		writeSideEffects(&input, session, opts, cfg, debugFile)
+	})
+}
+
+// writeSideEffects sends the progress indicator, alert notifications and
+// window title of a call to the terminal, after the status line
+func writeSideEffects(input *StatusLineInput, session *sessionState, opts options, cfg Config, debugFile *os.File) {
+	if opts.InputPath == "" {
+		emitProgress(cfg, input, debugFile)
+	}
+	if session != nil {
+		notifyAlerts(cfg.Alerts, session.Raised, debugFile)
+		if session.TitleChanged {
+			writeTTY(titleSequence(cfg.Title, session.Title), debugFile)
+		}
+	}
 }
 
 // trimNullBytes removes null bytes from input
@@ -132,90 +233,211 @@ const (
 	colorClear  = "\x1b[2J\x1b[H"  // Clear screen and move cursor to home
 )
 
+// fallbackHorseLine is printed when rendering fails completely
+const fallbackHorseLine = "🐴⏜))~"
+
 // renderStatusLineMulti renders the status line with multi-line output
+// A panic anywhere in the pipeline never reaches Claude's statusline:
+// the plain fallback horse is printed instead and the error is logged
+func renderStatusLineMulti(input *StatusLineInput, session *sessionState, debugFile *os.File, opts options, cfg Config) {
+	safeRender(os.Stdout, debugFile, func(w io.Writer) {
+		writeStatusLine(w, input, session, debugFile, opts, cfg)
+	})
+}
+
+// safeRender buffers the output of render and writes it to out only when
+// render completes; on panic it writes fallbackHorseLine instead
+func safeRender(out io.Writer, debugFile *os.File, render func(w io.Writer)) {
+	var buf bytes.Buffer
+	defer func() {
+		if r := recover(); r != nil {
+			debugLog(debugFile, "render panic: %v\n%s", r, debug.Stack())
+			fmt.Fprintln(out, fallbackHorseLine)
+		}
+	}()
+
+	render(&buf)
+	out.Write(buf.Bytes())
+}
+
+// safeCall runs fn, logging a panic to the debug file instead of letting
+// it reach Claude's statusline. It reports whether fn completed
+func safeCall(debugFile *os.File, name string, fn func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			debugLog(debugFile, "%s panic: %v\n%s", name, r, debug.Stack())
+			ok = false
+		}
+	}()
+
+	fn()
+	return true
+}
+
+// writeStatusLine renders the status line into w
 // Colors the horse sprite area red, dots remain default
-func renderStatusLineMulti(input *StatusLineInput, debugFile *os.File) {
-	now := time.Now()
-	horse := getHorseLines(debugFile, now)
-
-	// Find maximum sprite width across all frames
-	maxSpriteWidth := 0
-	for _, frame := range HorseSprite {
-		for _, line := range frame {
-			// Use terminal cell width (emoji = 2 cells, not 1)
-			lineWidth := StringWidth(line)
-			if lineWidth > maxSpriteWidth {
-				maxSpriteWidth = lineWidth
-			}
+func writeStatusLine(w io.Writer, input *StatusLineInput, session *sessionState, debugFile *os.File, opts options, cfg Config) {
+	now := opts.now()
+	theme := currentTheme()
+
+	// Segments are built first so the compact layout can fit the track next to them
+	status := buildStatusModel(input, horseState{}, session, cfg.Alerts.Rules)
+	segs := buildSegments(&segmentContext{Input: input, Status: status, Session: session, Config: cfg, Now: now, DebugFile: debugFile})
+	segLine := segmentLine(segs, theme)
+
+	cols, rows, _ := terminalSize()
+	compact := useCompactLayout(cfg.Layout, cols, rows)
+	draw := drawHorse
+	frameWidth := opts.trackWidth()
+	if compact {
+		draw = drawCompactHorse
+		if opts.Width == 0 {
+			frameWidth = compactWidth(cols, segLine.width())
 		}
 	}
 
-	for _, line := range horse {
-		// Process each line with per-character color logic (like animation mode)
-		// Dots and spaces remain default color, other characters are red
-		var result strings.Builder
-		inColor := false
+	// The session clock runs faster or slower with the output throughput,
+	// and the session's gait picks the sprite sequence
+	state := horseStateFor(session, now, frameWidth)
+	if opts.Gait != "" {
+		elapsed := time.Duration(now.UnixMilli()) * time.Millisecond
+		state = lookupGait(opts.Gait).stateAt(opts.Gait, elapsed, 0, frameWidth)
+	}
+	state = opts.applyOverrides(state)
+	logHorseState(debugFile, now, state)
+	status.Gait, status.Frame, status.Position, status.MaxPosition = state.Gait, state.Frame, state.Position, state.MaxPos
+
+	// The forecast marker shows how far off the context fill is, with the
+	// track standing for the horizon
+	track := draw(state, frameWidth)
+	if cfg.ETA.Marker {
+		track = drawForecastMarker(track, forecastColumn(status.Forecast, state.MaxPos, cfg.ETA.horizon()))
+	}
+
+	// A newly raised alert flashes the sprite for a few seconds
+	horseTheme := theme
+	if session.flashing(now, cfg.Alerts.flashDuration()) {
+		horseTheme.Sprite = flashColor
+	}
 
-		for _, ch := range line {
-			if ch == '.' || ch == ' ' {
-				if inColor {
-					result.WriteString(colorReset)
-					inColor = false
-				}
-				result.WriteRune(ch)
-			} else {
-				if !inColor {
-					result.WriteString(colorRed160)
-					inColor = true
-				}
-				result.WriteRune(ch)
+	frame := statusFrame{
+		Horse:    styleLines(track, horseTheme),
+		Segments: segLine,
+		Compact:  compact,
+	}
+
+	switch opts.Format {
+	case formatJSON:
+		data, _ := json.Marshal(status)
+		fmt.Fprintln(w, string(data))
+	case formatTmux:
+		fmt.Fprintln(w, renderTmux(frame, opts.Row))
+	case formatSVG, formatHTML:
+		frames := [][]styledLine{frame.lines()}
+		mo := markupOptions{}
+		if opts.Cycle {
+			var horses [][]string
+			horses, mo.FrameDelay = cycleFrames(now, frameWidth, opts, draw)
+			frames = frames[:0]
+			for _, horse := range horses {
+				frame.Horse = styleLines(horse, theme)
+				frames = append(frames, frame.lines())
 			}
 		}
-
-		// Reset color at end if needed
-		if inColor {
-			result.WriteString(colorReset)
+		if opts.Format == formatSVG {
+			fmt.Fprint(w, renderSVG(frames, mo))
+		} else {
+			fmt.Fprint(w, renderHTML(frames, mo))
 		}
+	default:
+		for _, line := range frame.lines() {
+			fmt.Fprintln(w, ansiLine(line))
+		}
+	}
+}
+
+// cycleFrames renders the animation cycle starting at now for --cycle
+// It returns the horse rows of every frame and the time each one is shown
+func cycleFrames(now time.Time, frameWidth int, opts options, draw func(horseState, int) []string) ([][]string, time.Duration) {
+	fps := opts.FPS
+	if fps == 0 {
+		fps = 4 // One frame per 250ms sprite frame
+	}
+	duration := opts.Duration
+	if duration == 0 {
+		duration = animationCycle(frameWidth)
+	}
 
-		fmt.Println(result.String())
+	var frames [][]string
+	for _, t := range frameTimes(now, fps, duration) {
+		frames = append(frames, draw(horseStateAt(t, frameWidth), frameWidth))
 	}
+	return frames, time.Second / time.Duration(fps)
+}
+
+// colorizeLine applies per-character coloring to a rendered horse line
+// Dots and spaces remain default color, other characters are red
+func colorizeLine(line string) string {
+	return ansiLine(styleLine(line, currentTheme()))
+}
+
+// horseState is the animation state of the horse at one instant
+type horseState struct {
+	Gait     string // Sprite sequence, see gaitSequences
+	Frame    int    // Sprite frame index within the gait
+	Position int    // Leading dots before the sprite
+	MaxPos   int    // Number of positions on the track
+}
+
+// defaultTrackWidth returns the terminal cell width of the full dotted track
+func defaultTrackWidth() int {
+	// Use terminal cell width for frame width (all lines have 95 cells)
+	return StringWidth(HorseFrames[0][0])
 }
 
 // getHorseLines returns the current frame of the horse animation
 // The horse moves right to left along a dotted path
 func getHorseLines(debugFile *os.File, now time.Time) []string {
-	// Frame animation: 250ms per frame
-	frameIndex := int(now.UnixMilli()/250) % NumFrames()
-	frame := HorseFrames[frameIndex]
-	sprite := GetHorseSprite(frameIndex)
+	return getHorseLinesWidth(debugFile, now, defaultTrackWidth())
+}
 
-	// Use terminal cell width for frame width (all lines have 95 cells)
-	frameWidth := StringWidth(frame[0])
+// getHorseLinesWidth returns the current frame of the horse animation
+// drawn on a track of the given terminal cell width
+func getHorseLinesWidth(debugFile *os.File, now time.Time, frameWidth int) []string {
+	state := horseStateAt(now, frameWidth)
+	logHorseState(debugFile, now, state)
+	return drawHorse(state, frameWidth)
+}
 
-	// Position animation: move right to left
-	// Starts at right, moves left, then wraps
-	maxPos := frameWidth - 20 // Leave space for horse width
-	position := (maxPos - (int(now.UnixMilli()/500) % maxPos))
+// horseStateAt computes the frame and track position for a point in time
+// This is the classic stateless gallop: 250ms per frame, 500ms per step,
+// moving right to left and wrapping around
+func horseStateAt(now time.Time, frameWidth int) horseState {
+	elapsed := time.Duration(now.UnixMilli()) * time.Millisecond
+	return lookupGait(gaitGallop).stateAt(gaitGallop, elapsed, 0, frameWidth)
+}
 
-	// Debug logging
-	if debugFile != nil {
-		lastCallTime, _, _ := loadLastCallState()
-		timeSinceLastCall := now.Sub(lastCallTime)
+// logHorseState writes the animation state to the debug log, if enabled
+func logHorseState(debugFile *os.File, now time.Time, state horseState) {
+	if debugFile == nil {
+		return
+	}
 
-		debugMsg := fmt.Sprintf(
-			"[%s] frame=%d/%d position=%d/%d time_since_last=%v\n",
-			now.Format("2006-01-02 15:04:05.000"),
-			frameIndex,
-			NumFrames(),
-			position,
-			maxPos,
-			timeSinceLastCall,
-		)
-		debugFile.WriteString(debugMsg)
+	debugFile.WriteString(fmt.Sprintf(
+		"[%s] gait=%s frame=%d/%d position=%d/%d\n",
+		now.Format("2006-01-02 15:04:05.000"),
+		state.Gait,
+		state.Frame,
+		len(lookupGait(state.Gait).Frames),
+		state.Position,
+		state.MaxPos,
+	))
+}
 
-		// Save current state
-		saveLastCallState(now, frameIndex, position)
-	}
+// drawHorse builds the dotted track rows with the sprite at the state position
+func drawHorse(state horseState, frameWidth int) []string {
+	sprite := gaitSprite(state.Gait, state.Frame)
+	position := state.Position
 
 	// Create result with dotted path
 	result := make([]string, 4)
@@ -240,7 +462,7 @@ func getHorseLines(debugFile *os.File, now time.Time) []string {
 			}
 
 			// Fill remaining space with dots to maintain visual alignment
-			// All lines have 95 terminal cells (visual width is consistent)
+			// All lines have the same number of terminal cells
 			spriteLineWidth := StringWidth(spriteLine)
 			remaining := frameWidth - position - spriteLineWidth
 			if remaining > 0 {
@@ -256,12 +478,27 @@ func getHorseLines(debugFile *os.File, now time.Time) []string {
 			}
 		}
 
-		result[i] = row.String()
+		// Narrow tracks may not fit the sprite at its rightmost position
+		result[i] = TruncateWidth(row.String(), frameWidth)
 	}
 
 	return result
 }
 
+// trackMaxPosition returns the number of positions the horse can take on
+// a track of the given width, always at least 1
+func trackMaxPosition(frameWidth int) int {
+	maxPos := frameWidth - 20 // Leave space for horse width
+	if maxPos < 1 {
+		// Narrow track: only keep room for the widest sprite line
+		maxPos = frameWidth - MaxSpriteWidth()
+	}
+	if maxPos < 1 {
+		maxPos = 1
+	}
+	return maxPos
+}
+
 func basename(path string) string {
 	// Handle both Unix and Windows paths
 	path = strings.ReplaceAll(path, "\\", "/")
@@ -285,6 +522,12 @@ A Claude Code statusline plugin that displays an animated red galloping horse.
 
 Usage:
   statusline [flags]
+  statusline export gif [--out file] [--width cells] [--scale n] [--fps n] [--duration d]
+  statusline export cast [--out file] [--width cols] [--fps n] [--duration d]
+  statusline tmux [--row n] [--width cells]
+  statusline inspect [--input file]
+  statusline replay <capture> [--speed x]
+  statusline simulate [--speed x]
 
 Flags:
   -h, --help     Show this help message
@@ -292,6 +535,48 @@ Flags:
   -a, --animate  Run continuous animation in terminal (press Ctrl+C to exit)
   -d, --debug    Enable debug logging to track call timing and animation state
 
+Render overrides (reproduce an exact frame for bug reports and golden tests):
+  --at <time>       Render at a fixed time (RFC3339 or unix milliseconds)
+  --frame <n>       Force the sprite frame (0-7 for the gallop, fewer for other gaits)
+  --position <n>    Force the track position (leading dots)
+  --gait <name>     Force the gait: walk, trot, canter, gallop, rear, whip or rest
+  --input <file>    Read the JSON payload from a file instead of stdin
+
+Output:
+  -f, --format <f>  Output format: ansi (default), svg, html, json, tmux
+  --cycle           With svg/html, render the full animation cycle as CSS keyframes
+  --row <n>         With tmux, the horse row to keep (default 1)
+  --width <cells>   Track width in terminal cells (default 95)
+
+Layout:
+  --layout <l>      full, compact (one row) or auto (default, compact on small terminals)
+  --record          Append each payload to the capture file for replay
+  --progress <m>    Show context or rate_limit usage as a terminal progress bar
+                    (OSC 9;4), or off
+  --title           Set the terminal window title to a session summary (OSC 0/2)
+  --config <file>   Config file (default: user config dir/claude-ride-with-whip/config.json,
+                    or $CLAUDE_RIDE_CONFIG)
+
+The tmux command renders the last payload Claude Code sent, so it can run
+from tmux status-right without stdin:
+  set -g status-right '#(statusline tmux --width 40)'
+
+The inspect command reads a payload like a normal render and reports syntax
+errors and type mismatches with line:column, unknown and missing fields, the
+normalized input and the text of every configured segment.
+
+The replay command plays a capture file back through the renderer, at the
+original pace or --speed times faster, redrawing every 100ms like --animate.
+The simulate command plays a synthetic two-minute session the same way,
+passing through every context, rate limit and model state.
+
+Export options:
+  -o, --out <file>      Output file ("-" for stdout, default horse.gif / horse.cast)
+  --width <cells>       Track or terminal width in cells (default 95)
+  --scale <n>           Pixel scale factor for gif (default 1)
+  --fps <n>             Frames per second (default 4 for gif, at most 50; 10 for cast)
+  --duration <d>        Animation length, e.g. 10s (default one lap of the track)
+
 This plugin reads JSON input from stdin and outputs a red horse ASCII art.
 The horse animation cycles through 8 frames to create a galloping effect.
 
@@ -301,106 +586,21 @@ Animation timing:
 `)
 }
 
-// runAnimationMode runs continuous animation in the terminal
-func runAnimationMode() {
-	// Clear screen once at start
-	fmt.Print(colorClear)
-
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		// Clear screen and move cursor to home
-		fmt.Print(colorClear)
-
-		// Display header
-		fmt.Println(colorRed160 + "╔════════════════════════════════════════════════════════════╗" + colorReset)
-		fmt.Println(colorRed160 + "║" + colorReset + "         🐴 Claude Ride With Whip - Animation Demo 🐴         " + colorRed160 + "║" + colorReset)
-		fmt.Println(colorRed160 + "║" + colorReset + "                  Press Ctrl+C to exit                    " + colorRed160 + "║" + colorReset)
-		fmt.Println(colorRed160 + "╚════════════════════════════════════════════════════════════╝" + colorReset)
-		fmt.Println()
-
-		// Render horse
-		horse := getHorseLines(nil, time.Now())
-		for _, line := range horse {
-			var result strings.Builder
-			inColor := false
-
-			for _, ch := range line {
-				if ch == '.' || ch == ' ' {
-					if inColor {
-						result.WriteString(colorReset)
-						inColor = false
-					}
-					result.WriteRune(ch)
-				} else {
-					if !inColor {
-						result.WriteString(colorRed160)
-						inColor = true
-					}
-					result.WriteRune(ch)
-				}
-			}
-
-			if inColor {
-				result.WriteString(colorReset)
-			}
-
-			fmt.Println(result.String())
-		}
-
-		fmt.Println()
-		fmt.Println(colorRed160 + "✨ 马到成功 · 一马当先 · 龙马精神 ✨" + colorReset)
+// debugLog writes a timestamped message to the debug log, if enabled
+func debugLog(debugFile *os.File, format string, args ...any) {
+	if debugFile == nil {
+		return
 	}
+	debugFile.WriteString(fmt.Sprintf(
+		"[%s] %s\n",
+		time.Now().Format("2006-01-02 15:04:05.000"),
+		fmt.Sprintf(format, args...),
+	))
 }
 
-// Debug state file path
-const debugStateFile = "claude_statusline_debug_state.json"
-
 // getDebugFilePath returns the path to the debug log file
 func getDebugFilePath() string {
 	// Use temp directory
 	cacheDir := os.TempDir()
 	return filepath.Join(cacheDir, "claude_statusline_debug.log")
 }
-
-// CallState stores the last call state for debug mode
-type CallState struct {
-	LastCallTime int64 `json:"last_call_time"`
-	FrameIndex   int   `json:"frame_index"`
-	Position     int   `json:"position"`
-}
-
-// loadLastCallState loads the previous call state from disk
-func loadLastCallState() (time.Time, int, int) {
-	cacheDir := os.TempDir()
-	statePath := filepath.Join(cacheDir, debugStateFile)
-
-	data, err := os.ReadFile(statePath)
-	if err != nil {
-		// First run or state file doesn't exist
-		return time.Time{}, 0, 0
-	}
-
-	var state CallState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return time.Time{}, 0, 0
-	}
-
-	return time.UnixMilli(state.LastCallTime), state.FrameIndex, state.Position
-}
-
-// saveLastCallState saves the current call state to disk
-func saveLastCallState(now time.Time, frameIndex, position int) {
-	cacheDir := os.TempDir()
-	statePath := filepath.Join(cacheDir, debugStateFile)
-
-	state := CallState{
-		LastCallTime: now.UnixMilli(),
-		FrameIndex:   frameIndex,
-		Position:     position,
-	}
-
-	data, _ := json.Marshal(state)
-	_ = os.WriteFile(statePath, data, 0644)
-}
@@ -2,11 +2,17 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetHorseLines_LineAlignment(t *testing.T) {
@@ -206,3 +212,68 @@ func BenchmarkGetHorseLines(b *testing.B) {
 		getHorseLines(nil, now)
 	}
 }
+
+func TestSafeRender_FallbackOnPanic(t *testing.T) {
+	var out bytes.Buffer
+	safeRender(&out, nil, func(w io.Writer) {
+		io.WriteString(w, "partial output\n")
+		panic("render exploded")
+	})
+
+	// Partial output is discarded, only the plain horse is printed
+	assert.Equal(t, fallbackHorseLine+"\n", out.String())
+}
+
+func TestSafeRender_PassesOutputThrough(t *testing.T) {
+	var out bytes.Buffer
+	safeRender(&out, nil, func(w io.Writer) {
+		io.WriteString(w, "line 1\nline 2\n")
+	})
+	assert.Equal(t, "line 1\nline 2\n", out.String())
+}
+
+func TestSafeCall_RecoversAndLogs(t *testing.T) {
+	debugFile, err := os.Create(filepath.Join(t.TempDir(), "debug.log"))
+	require.NoError(t, err)
+	defer debugFile.Close()
+
+	ok := safeCall(debugFile, "session", func() {
+		panic("state exploded")
+	})
+	assert.False(t, ok)
+
+	logged, err := os.ReadFile(debugFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(logged), "session panic: state exploded")
+	assert.Contains(t, string(logged), "goroutine", "the stack trace is logged")
+
+	assert.True(t, safeCall(nil, "tty", func() {}))
+}
+
+func TestLogHorseState_FramesOfTheGait(t *testing.T) {
+	debugFile, err := os.Create(filepath.Join(t.TempDir(), "debug.log"))
+	require.NoError(t, err)
+	defer debugFile.Close()
+
+	logHorseState(debugFile, time.UnixMilli(0), horseState{Gait: gaitWalk, Frame: 2, Position: 10, MaxPos: 60})
+
+	logged, err := os.ReadFile(debugFile.Name())
+	require.NoError(t, err)
+	want := fmt.Sprintf("gait=walk frame=2/%d position=10/60", len(lookupGait(gaitWalk).Frames))
+	assert.Contains(t, string(logged), want)
+}
+
+func TestWriteStatusLine_FullLayout(t *testing.T) {
+	var out bytes.Buffer
+	input := &StatusLineInput{}
+	input.Model.DisplayName = "Opus"
+	cfg := defaultConfig()
+	cfg.Layout = layoutFull
+	cfg.Segments = []string{"model"}
+
+	writeStatusLine(&out, input, nil, nil, options{At: time.UnixMilli(0), Frame: -1, Position: -1, Format: formatANSI}, cfg)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 5, "four horse rows plus the segment row")
+	assert.Equal(t, "Opus", lines[4])
+}
@@ -0,0 +1,176 @@
+// Package main provides the SVG and HTML render backends
+package main
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"strings"
+	"time"
+)
+
+// Monospace cell metrics used by the markup backends, in pixels
+const (
+	markupCellWidth  = 10
+	markupCellHeight = 20
+	markupFontSize   = 16
+)
+
+// markupOptions controls SVG and HTML rendering
+type markupOptions struct {
+	FrameDelay time.Duration // Time each frame is shown in an animated cycle
+}
+
+// cellSpan is a piece of text placed at a fixed terminal cell column
+type cellSpan struct {
+	Col   int    // First cell column
+	Cells int    // Width in cells
+	Text  string // Unescaped text
+	Color int    // xterm 256-color index or colorDefault
+}
+
+// cellSpans splits a line into spans whose cell positions come from
+// StringWidth, so emoji and halfwidth characters line up exactly.
+// Wide characters always get a span of their own.
+func cellSpans(line styledLine) []cellSpan {
+	var spans []cellSpan
+	col := 0
+	for _, run := range line {
+		extendable := false // Last span is narrow and belongs to this run
+		for _, ch := range run.Text {
+			w := StringWidth(string(ch))
+			if w == 0 {
+				continue
+			}
+			if !extendable || w != 1 {
+				spans = append(spans, cellSpan{Col: col, Color: run.Color})
+			}
+			last := &spans[len(spans)-1]
+			last.Text += string(ch)
+			last.Cells += w
+			col += w
+			extendable = w == 1
+		}
+	}
+	return spans
+}
+
+// cssColor formats a theme color as a CSS hex color
+func cssColor(c int) string {
+	return cssHex(xterm256RGB(c))
+}
+
+// cssHex formats an RGBA color as a CSS hex color
+func cssHex(rgb color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", rgb.R, rgb.G, rgb.B)
+}
+
+// frameSize returns the widest line in cells and the number of rows
+func frameSize(frames [][]styledLine) (cols, rows int) {
+	for _, frame := range frames {
+		for _, line := range frame {
+			if w := line.width(); w > cols {
+				cols = w
+			}
+		}
+		if len(frame) > rows {
+			rows = len(frame)
+		}
+	}
+	return cols, rows
+}
+
+// cycleCSS returns the keyframes showing each frame for its slot of the cycle
+// Every frame shares one animation and is offset with animation-delay
+func cycleCSS(frameCount int, delay time.Duration) string {
+	if frameCount <= 1 {
+		return ""
+	}
+	total := time.Duration(frameCount) * delay
+	slot := 100.0 / float64(frameCount)
+	return fmt.Sprintf(
+		"@keyframes horse-cycle { 0%% { visibility: visible; } %.4f%%, 100%% { visibility: hidden; } }\n"+
+			".frame { visibility: hidden; animation: horse-cycle %.3fs step-end infinite; }\n",
+		slot, total.Seconds(),
+	)
+}
+
+// frameDelayStyle returns the inline animation-delay of frame i
+func frameDelayStyle(i, frameCount int, delay time.Duration) string {
+	if frameCount <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(` style="animation-delay: %.3fs"`, (time.Duration(i) * delay).Seconds())
+}
+
+// renderSVG turns rendered frames into a standalone SVG document
+// A single frame is static; several frames become a CSS keyframes cycle
+func renderSVG(frames [][]styledLine, o markupOptions) string {
+	cols, rows := frameSize(frames)
+	width := cols * markupCellWidth
+	height := rows * markupCellHeight
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, "<style>\ntext { font-family: ui-monospace, Menlo, Consolas, monospace; font-size: %dpx; white-space: pre; }\n%s</style>\n",
+		markupFontSize, cycleCSS(len(frames), o.FrameDelay))
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", cssHex(gifBackground))
+
+	for i, frame := range frames {
+		class := ""
+		if len(frames) > 1 {
+			class = ` class="frame"`
+		}
+		fmt.Fprintf(&b, "<g%s%s>\n", class, frameDelayStyle(i, len(frames), o.FrameDelay))
+		for row, line := range frame {
+			baseline := row*markupCellHeight + markupCellHeight*3/4
+			for _, span := range cellSpans(line) {
+				fmt.Fprintf(&b, `<text x="%d" y="%d" fill="%s" textLength="%d" lengthAdjust="spacingAndGlyphs">%s</text>`+"\n",
+					span.Col*markupCellWidth, baseline, cssColor(span.Color),
+					span.Cells*markupCellWidth, html.EscapeString(span.Text))
+			}
+		}
+		b.WriteString("</g>\n")
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// renderHTML turns rendered frames into a standalone HTML document
+// Each span is an inline block exactly as wide as its terminal cells
+func renderHTML(frames [][]styledLine, o markupOptions) string {
+	cols, rows := frameSize(frames)
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Claude Ride With Whip</title>\n<style>\n")
+	fmt.Fprintf(&b, "body { margin: 0; background: %s; }\n", cssHex(gifBackground))
+	fmt.Fprintf(&b, ".screen { position: relative; width: %dpx; height: %dpx; font-family: ui-monospace, Menlo, Consolas, monospace; font-size: %dpx; line-height: %dpx; }\n",
+		cols*markupCellWidth, rows*markupCellHeight, markupFontSize, markupCellHeight)
+	b.WriteString(".screen > div { position: absolute; top: 0; left: 0; }\n")
+	fmt.Fprintf(&b, ".row { height: %dpx; white-space: pre; }\n", markupCellHeight)
+	b.WriteString(".row span { display: inline-block; overflow: hidden; }\n")
+	b.WriteString(cycleCSS(len(frames), o.FrameDelay))
+	b.WriteString("</style>\n</head>\n<body>\n<div class=\"screen\">\n")
+
+	for i, frame := range frames {
+		class := ""
+		if len(frames) > 1 {
+			class = ` class="frame"`
+		}
+		fmt.Fprintf(&b, "<div%s%s>\n", class, frameDelayStyle(i, len(frames), o.FrameDelay))
+		for _, line := range frame {
+			b.WriteString(`<div class="row">`)
+			for _, span := range cellSpans(line) {
+				fmt.Fprintf(&b, `<span style="width:%dpx;color:%s">%s</span>`,
+					span.Cells*markupCellWidth, cssColor(span.Color), html.EscapeString(span.Text))
+			}
+			b.WriteString("</div>\n")
+		}
+		b.WriteString("</div>\n")
+	}
+
+	b.WriteString("</div>\n</body>\n</html>\n")
+	return b.String()
+}
@@ -0,0 +1,66 @@
+// Package main provides tests for the SVG and HTML render backends
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCellSpans_WideCharactersAlign(t *testing.T) {
+	spans := cellSpans(styleLine("..🐴⏜)) ﾉﾉ..", defaultTheme))
+
+	assert.Equal(t, []cellSpan{
+		{Col: 0, Cells: 2, Text: "..", Color: colorDefault},
+		{Col: 2, Cells: 2, Text: "🐴", Color: 160},
+		{Col: 4, Cells: 3, Text: "⏜))", Color: 160},
+		{Col: 7, Cells: 1, Text: " ", Color: colorDefault},
+		{Col: 8, Cells: 2, Text: "ﾉﾉ", Color: 160},
+		{Col: 10, Cells: 2, Text: "..", Color: colorDefault},
+	}, spans)
+}
+
+func TestRenderSVG_WellFormed(t *testing.T) {
+	frames := [][]styledLine{styleLines(getHorseLines(nil, time.UnixMilli(0)), defaultTheme)}
+	svg := renderSVG(frames, markupOptions{})
+
+	// Must parse as XML
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		_, err := decoder.Token()
+		if err != nil {
+			assert.Equal(t, "EOF", err.Error())
+			break
+		}
+	}
+
+	assert.Contains(t, svg, `width="950"`, "95 cells of 10px")
+	assert.Contains(t, svg, `fill="#d70000"`, "sprite uses theme color 160")
+	assert.NotContains(t, svg, "@keyframes", "a single frame is static")
+}
+
+func TestRenderSVG_EscapesText(t *testing.T) {
+	svg := renderSVG([][]styledLine{{styleLine("<&>", defaultTheme)}}, markupOptions{})
+	assert.Contains(t, svg, "&lt;&amp;&gt;")
+}
+
+func TestRenderHTML_Cycle(t *testing.T) {
+	horses, delay := cycleFrames(time.UnixMilli(0), 40, options{FPS: 4, Duration: time.Second}, drawHorse)
+	require.Len(t, horses, 4)
+	assert.Equal(t, 250*time.Millisecond, delay)
+
+	var frames [][]styledLine
+	for _, horse := range horses {
+		frames = append(frames, styleLines(horse, defaultTheme))
+	}
+	page := renderHTML(frames, markupOptions{FrameDelay: delay})
+	assert.Contains(t, page, "@keyframes horse-cycle")
+	assert.Contains(t, page, `animation-delay: 0.750s`)
+	assert.Equal(t, 4, strings.Count(page, `class="frame"`))
+	assert.Contains(t, page, `<span style="width:20px;color:#d70000">🐴</span>`,
+		"emoji spans two cells")
+}
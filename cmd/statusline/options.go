@@ -0,0 +1,301 @@
+// Package main provides command line option parsing
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats selectable with --format
+const (
+	formatANSI = "ansi"
+	formatSVG  = "svg"
+	formatHTML = "html"
+	formatJSON = "json"
+	formatTmux = "tmux"
+)
+
+// outputFormats lists every valid --format value
+var outputFormats = []string{formatANSI, formatSVG, formatHTML, formatJSON, formatTmux}
+
+// options holds the parsed command line flags
+type options struct {
+	ShowHelp    bool
+	ShowVersion bool
+	Animate     bool
+	Debug       bool
+
+	// Deterministic render overrides for snapshots and bug reports
+	At        time.Time // Render clock, zero means time.Now()
+	Frame     int       // Sprite frame override, -1 means time based
+	Position  int       // Track position override, -1 means time based
+	Gait      string    // Gait override, empty means session based
+	InputPath string    // Read the payload from this file instead of stdin
+
+	// Output backend
+	Format string // One of outputFormats
+	Cycle  bool   // Render the full animation cycle instead of one frame
+	Row    int    // Horse row kept by single-row formats such as tmux
+
+	// Configuration
+	ConfigPath string // Config file, empty for the default location
+	Layout     string // Layout override, empty to use the config
+	Record     bool   // Append the stdin payload to the capture file
+	Progress   string // Progress indicator override, empty to use the config
+	Title      bool   // Send the window title
+
+	// Replay settings
+	Speed float64 // Replay speed factor, 0 for real time
+
+	// Export settings
+	Out      string        // Output file, "-" for stdout
+	Width    int           // Track width in cells, 0 for the default
+	Scale    int           // Pixel scale factor, 0 for the default
+	FPS      int           // Frames per second, 0 for the default
+	Duration time.Duration // Export length, 0 for one lap of the track
+
+	// Positional arguments (subcommands and their operands)
+	Args []string
+}
+
+// parseArgs parses command line arguments into options
+// Both "--flag value" and "--flag=value" forms are accepted for value flags;
+// unknown flags are ignored so newer Claude Code versions never break the plugin.
+// Before the subcommand, a bare word after an unknown flag is taken as its
+// value unless it names a subcommand, so "--new-flag value" never turns
+// value into an unknown command
+func parseArgs(args []string) (options, error) {
+	opts := options{Frame: -1, Position: -1, Format: formatANSI, Row: defaultTmuxRow}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+
+		// nextValue returns the flag value from "=value" or the next argument
+		nextValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch name {
+		case "--version", "-v":
+			opts.ShowVersion = true
+		case "--help", "-h":
+			opts.ShowHelp = true
+		case "--animate", "-a":
+			opts.Animate = true
+		case "--debug", "-d":
+			opts.Debug = true
+		case "--at":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.At, err = parseAtTime(v)
+			}
+		case "--frame":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Frame, err = parseNonNegative(name, v)
+			}
+		case "--position":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Position, err = parseNonNegative(name, v)
+			}
+		case "--gait":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Gait = v
+				if _, ok := gaitSequences[v]; !ok {
+					err = fmt.Errorf("invalid --gait %q: want walk, trot, canter, gallop, rear, whip or rest", v)
+				}
+			}
+		case "--input":
+			opts.InputPath, err = nextValue()
+		case "--format", "-f":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Format, err = parseFormat(v)
+			}
+		case "--cycle":
+			opts.Cycle = true
+		case "--row":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Row, err = parseNonNegative(name, v)
+			}
+		case "--config":
+			opts.ConfigPath, err = nextValue()
+		case "--layout":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Layout = v
+				if v != layoutFull && v != layoutCompact && v != layoutAuto {
+					err = fmt.Errorf("invalid --layout %q: want full, compact or auto", v)
+				}
+			}
+		case "--record":
+			opts.Record = true
+		case "--title":
+			opts.Title = true
+		case "--progress":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Progress = v
+				err = validateProgress(v)
+			}
+		case "--speed":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Speed, err = strconv.ParseFloat(v, 64)
+				if err != nil || opts.Speed <= 0 {
+					err = fmt.Errorf("invalid --speed %q: want a positive number", v)
+				}
+			}
+		case "--out", "-o":
+			opts.Out, err = nextValue()
+		case "--width", "--scale", "--fps":
+			var v, n = "", 0
+			if v, err = nextValue(); err == nil {
+				n, err = parsePositive(name, v)
+			}
+			switch name {
+			case "--width":
+				opts.Width = n
+			case "--scale":
+				opts.Scale = n
+			case "--fps":
+				opts.FPS = n
+			}
+		case "--duration":
+			var v string
+			if v, err = nextValue(); err == nil {
+				opts.Duration, err = time.ParseDuration(v)
+				if err == nil && opts.Duration <= 0 {
+					err = fmt.Errorf("invalid --duration %q: must be positive", v)
+				}
+			}
+		default:
+			if !strings.HasPrefix(arg, "-") {
+				opts.Args = append(opts.Args, arg)
+			} else if !hasValue && len(opts.Args) == 0 && i+1 < len(args) {
+				next := args[i+1]
+				if !strings.HasPrefix(next, "-") && !slices.Contains(commandNames, next) {
+					i++ // Skip the unknown flag's value
+				}
+			}
+		}
+		if err != nil {
+			return opts, err
+		}
+	}
+
+	// A reproduction must render exactly the requested frame, never wrap
+	if opts.Frame >= 0 {
+		gait := opts.frameGait()
+		if n := len(lookupGait(gait).Frames); opts.Frame >= n {
+			return opts, fmt.Errorf("invalid --frame %d: the %s gait has frames 0-%d", opts.Frame, gait, n-1)
+		}
+	}
+
+	return opts, nil
+}
+
+// frameGait returns the gait --frame was validated against: --gait, or
+// the classic gallop
+func (o options) frameGait() string {
+	if o.Gait != "" {
+		return o.Gait
+	}
+	return gaitGallop
+}
+
+// parseAtTime parses an RFC3339 timestamp or Unix milliseconds
+func parseAtTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --at %q: want RFC3339 or unix milliseconds", value)
+	}
+	return t, nil
+}
+
+// parseNonNegative parses a non-negative integer flag value
+func parseNonNegative(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a non-negative integer", name, value)
+	}
+	return n, nil
+}
+
+// parseFormat validates an output format name
+func parseFormat(value string) (string, error) {
+	for _, f := range outputFormats {
+		if value == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid --format %q: want one of %s", value, strings.Join(outputFormats, ", "))
+}
+
+// parsePositive parses a strictly positive integer flag value
+func parsePositive(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a positive integer", name, value)
+	}
+	return n, nil
+}
+
+// overridesRender reports whether any deterministic render override is
+// set. Such a render reproduces a frame and must not advance the session
+func (o options) overridesRender() bool {
+	return !o.At.IsZero() || o.Frame >= 0 || o.Position >= 0 || o.Gait != ""
+}
+
+// now returns the render clock: the --at override or the current time
+func (o options) now() time.Time {
+	if o.At.IsZero() {
+		return time.Now()
+	}
+	return o.At
+}
+
+// trackWidth returns the --width override or the full track width
+func (o options) trackWidth() int {
+	if o.Width > 0 {
+		return o.Width
+	}
+	return defaultTrackWidth()
+}
+
+// applyOverrides replaces the time based frame and position with the
+// --frame and --position overrides, clamping the position to the track
+func (o options) applyOverrides(state horseState) horseState {
+	if o.Frame >= 0 {
+		// A session gait without the frame draws the gait it was checked against
+		if o.Frame >= len(lookupGait(state.Gait).Frames) {
+			state.Gait = o.frameGait()
+		}
+		state.Frame = o.Frame
+	}
+	if o.Position >= 0 {
+		state.Position = o.Position
+		if state.Position > state.MaxPos {
+			state.Position = state.MaxPos
+		}
+	}
+	return state
+}
@@ -0,0 +1,122 @@
+// Package main provides tests for command line option parsing
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArgs_RenderOverrides(t *testing.T) {
+	opts, err := parseArgs([]string{
+		"--at", "2026-01-02T03:04:05Z",
+		"--frame=3",
+		"--position", "12",
+		"--input", "payload.json",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), opts.At.UTC())
+	assert.Equal(t, 3, opts.Frame)
+	assert.Equal(t, 12, opts.Position)
+	assert.Equal(t, "payload.json", opts.InputPath)
+}
+
+func TestParseArgs_Defaults(t *testing.T) {
+	opts, err := parseArgs([]string{"-d", "--unknown-flag"})
+	require.NoError(t, err)
+
+	assert.True(t, opts.Debug)
+	assert.True(t, opts.At.IsZero())
+	assert.Equal(t, -1, opts.Frame, "frame should be time based by default")
+	assert.Equal(t, -1, opts.Position, "position should be time based by default")
+}
+
+func TestParseArgs_UnknownFlagValues(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "value is not a command", args: []string{"--bogus", "value"}},
+		{name: "inline value", args: []string{"--bogus=value", "inspect"}, want: []string{"inspect"}},
+		{name: "command after a bare flag", args: []string{"--bogus", "inspect"}, want: []string{"inspect"}},
+		{name: "operands after the command", args: []string{"replay", "--bogus", "x.jsonl"}, want: []string{"replay", "x.jsonl"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseArgs(tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, opts.Args)
+		})
+	}
+}
+
+func TestParseArgs_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing value", args: []string{"--frame"}},
+		{name: "negative frame", args: []string{"--frame", "-1"}},
+		{name: "frame beyond the gallop", args: []string{"--frame", "8"}},
+		{name: "frame beyond the gait", args: []string{"--gait", "walk", "--frame", "4"}},
+		{name: "bad position", args: []string{"--position=abc"}},
+		{name: "bad time", args: []string{"--at", "yesterday"}},
+		{name: "zero speed", args: []string{"--speed", "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseArgs(tt.args)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseAtTime_UnixMillis(t *testing.T) {
+	at, err := parseAtTime("1700000000123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1700000000123), at.UnixMilli())
+}
+
+func TestApplyOverrides_Deterministic(t *testing.T) {
+	// The same overrides must reproduce the exact same frame at any time
+	opts := options{Frame: 2, Position: 10}
+	width := defaultTrackWidth()
+
+	first := drawHorse(opts.applyOverrides(horseStateAt(time.UnixMilli(0), width)), width)
+	second := drawHorse(opts.applyOverrides(horseStateAt(time.UnixMilli(123456), width)), width)
+	assert.Equal(t, first, second)
+	assert.Equal(t, "..........🐴⏜))~~~", first[1][:len("..........🐴⏜))~~~")])
+
+	// A session gait with fewer frames falls back to the gallop
+	walking := horseState{Gait: gaitWalk, Position: 10, MaxPos: 75}
+	state := options{Frame: 6, Position: -1}.applyOverrides(walking)
+	assert.Equal(t, gaitGallop, state.Gait)
+	assert.Equal(t, 6, state.Frame)
+
+	// Positions beyond the track are clamped
+	state = options{Frame: -1, Position: 500}.applyOverrides(horseStateAt(time.UnixMilli(0), width))
+	assert.Equal(t, state.MaxPos, state.Position)
+}
+
+func TestOptions_OverridesRender(t *testing.T) {
+	opts, err := parseArgs(nil)
+	require.NoError(t, err)
+	assert.False(t, opts.overridesRender())
+
+	for _, args := range [][]string{
+		{"--at", "2030-01-01T00:00:00Z"},
+		{"--frame", "2"},
+		{"--position", "5"},
+		{"--gait", "walk"},
+	} {
+		opts, err := parseArgs(args)
+		require.NoError(t, err)
+		assert.True(t, opts.overridesRender(), args[0])
+	}
+}
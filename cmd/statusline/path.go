@@ -0,0 +1,272 @@
+// Package main provides the directory path segment
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Path display styles
+const (
+	pathStyleRelative = "relative" // Relative to the project, else home-abbreviated
+	pathStyleFull     = "full"     // Home-abbreviated absolute path
+)
+
+// pathEllipsis marks elided path components
+const pathEllipsis = "…"
+
+// pathConfig controls the path segment
+type pathConfig struct {
+	Style      string   `json:"style"`       // relative or full
+	MaxWidth   int      `json:"max_width"`   // Cell budget, elided in the middle beyond it
+	Redact     []string `json:"redact"`      // Regular expressions hidden from the display
+	RedactWith string   `json:"redact_with"` // Replacement for redacted matches
+}
+
+// defaultPathConfig returns the path segment defaults
+func defaultPathConfig() pathConfig {
+	return pathConfig{
+		Style:      pathStyleRelative,
+		MaxWidth:   30,
+		RedactWith: "***",
+	}
+}
+
+// validate checks the style and compiles every redaction pattern
+func (c pathConfig) validate() error {
+	if c.Style != pathStyleRelative && c.Style != pathStyleFull {
+		return fmt.Errorf("invalid path style %q: want relative or full", c.Style)
+	}
+	if _, err := compileRedactions(c.Redact); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compileRedactions compiles user redaction patterns
+func compileRedactions(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// pathSegment shows the working directory for screen-sharing friendly display
+func pathSegment(ctx *segmentContext) segment {
+	if ctx.Input == nil {
+		return segment{Color: colorDefault}
+	}
+	home, _ := os.UserHomeDir()
+	dir := workingDir(ctx.Input)
+	if dir == "" {
+		dir = ctx.Input.Workspace.ProjectDir
+	}
+	text := displayPath(ctx.Input.Workspace.ProjectDir, dir, home, ctx.Config.Path)
+	seg := segment{Text: text, Color: colorDefault}
+	if !ctx.Config.Path.redacts(dir) {
+		seg.Link = dir
+	}
+	return seg
+}
+
+// redacts reports whether redaction changes path, matched the same way
+// displayPath matches it. A link to such a path would reveal what the
+// display hides, even a match the display cuts off
+func (c pathConfig) redacts(path string) bool {
+	return c.redactedPath(path) != path
+}
+
+// transcriptSegment shows the transcript file name, linked to the file
+func transcriptSegment(ctx *segmentContext) segment {
+	if ctx.Input == nil || ctx.Input.TranscriptPath == "" {
+		return segment{Color: colorDefault}
+	}
+	path := ctx.Input.TranscriptPath
+	seg := segment{Text: "📜 " + basename(path), Color: colorDefault}
+	if !ctx.Config.Path.redacts(path) {
+		seg.Link = path
+	}
+	return seg
+}
+
+// displayPath formats the current directory according to the path config
+// Redaction patterns see the absolute paths with "/" separators, before
+// home abbreviation and project-relative display, so they can be written
+// against real paths; elision runs last, so a redacted name is never half
+// visible
+func displayPath(projectDir, currentDir, home string, cfg pathConfig) string {
+	if currentDir == "" {
+		currentDir = projectDir
+	}
+	if currentDir == "" {
+		return ""
+	}
+
+	// Work with forward slashes and restore Windows separators at the end
+	sep := "/"
+	if strings.Contains(currentDir, "\\") && !strings.Contains(currentDir, "/") {
+		sep = "\\"
+	}
+	current := cleanSlashPath(currentDir)
+	project := cleanSlashPath(projectDir)
+
+	// The display is a tail of the absolute path after a prefix is cut:
+	// the project's parent directories, or the home directory as "~"
+	var prefix string
+	var cut int
+	if _, ok := relativeTo(current, project); ok && cfg.Style == pathStyleRelative {
+		cut = len(project) - len(basename(project))
+	} else {
+		prefix, cut = homePrefix(current, cleanSlashPath(home))
+	}
+
+	text, crossed := cfg.redactFrom(current, cut)
+	if !crossed {
+		text = prefix + text
+	}
+	text = elidePath(text, cfg.MaxWidth)
+	return strings.ReplaceAll(text, "/", sep)
+}
+
+// redactFrom redacts every match of the redaction patterns in path and
+// returns the redacted text from byte offset cut of the original path on.
+// A match crossing cut is shown whole as the replacement, and crossed
+// reports that the text before cut was partly redacted
+func (c pathConfig) redactFrom(path string, cut int) (text string, crossed bool) {
+	res, err := compileRedactions(c.Redact)
+	if err != nil {
+		return path[cut:], false
+	}
+	for _, re := range res {
+		var b strings.Builder
+		last, shift := 0, 0
+		for _, m := range re.FindAllStringIndex(path, -1) {
+			b.WriteString(path[last:m[0]])
+			b.WriteString(c.RedactWith)
+			last = m[1]
+			switch {
+			case m[1] <= cut:
+				shift += len(c.RedactWith) - (m[1] - m[0])
+			case m[0] < cut:
+				shift += m[0] - cut
+				crossed = true
+			}
+		}
+		b.WriteString(path[last:])
+		path, cut = b.String(), cut+shift
+	}
+	return path[cut:], crossed
+}
+
+// redactedName returns the base name of path as displayPath would show it:
+// the patterns see the whole absolute path, so a match covering the name
+// replaces it
+func (c pathConfig) redactedName(path string) string {
+	p := cleanSlashPath(path)
+	text, _ := c.redactFrom(p, len(p)-len(basename(p)))
+	return text
+}
+
+// redactedPath returns path with every redaction pattern match replaced,
+// keeping its separators
+func (c pathConfig) redactedPath(path string) string {
+	p := cleanSlashPath(path)
+	text, _ := c.redactFrom(p, 0)
+	if text == p {
+		return path
+	}
+	if strings.Contains(path, "\\") && !strings.Contains(path, "/") {
+		text = strings.ReplaceAll(text, "/", "\\")
+	}
+	return text
+}
+
+// cleanSlashPath converts separators to "/" and drops a trailing one
+func cleanSlashPath(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	if len(p) > 1 {
+		p = strings.TrimRight(p, "/")
+	}
+	return p
+}
+
+// relativeTo returns path relative to base when path is inside base
+func relativeTo(path, base string) (string, bool) {
+	if base == "" || base == "/" {
+		return "", false
+	}
+	if path == base {
+		return "", true
+	}
+	if rel, ok := strings.CutPrefix(path, base+"/"); ok {
+		return rel, true
+	}
+	return "", false
+}
+
+// homePrefix returns the "~" prefix that replaces the home directory in
+// path and the byte offset where the rest of path starts, or no prefix
+// and 0 outside the home directory
+func homePrefix(path, home string) (prefix string, cut int) {
+	if home == "" || home == "/" {
+		return "", 0
+	}
+	if path == home {
+		return "~", len(path)
+	}
+	if strings.HasPrefix(path, home+"/") {
+		return "~/", len(home) + 1
+	}
+	return "", 0
+}
+
+// elidePath shortens a slash separated path to maxWidth cells by replacing
+// middle components with "…", keeping the first component and as many
+// trailing components as fit. A zero or negative budget disables elision
+func elidePath(path string, maxWidth int) string {
+	if maxWidth <= 0 || StringWidth(path) <= maxWidth {
+		return path
+	}
+
+	parts := strings.Split(path, "/")
+	last := parts[len(parts)-1]
+	if len(parts) > 2 {
+		head := parts[0] + "/" + pathEllipsis
+		tail := last
+		for i := len(parts) - 2; i > 0; i-- {
+			if StringWidth(head+"/"+parts[i]+"/"+tail) > maxWidth {
+				break
+			}
+			tail = parts[i] + "/" + tail
+		}
+		if candidate := head + "/" + tail; StringWidth(candidate) <= maxWidth {
+			return candidate
+		}
+	}
+
+	// Even the last component is too long: keep its end
+	return pathEllipsis + truncateLeft(last, maxWidth-StringWidth(pathEllipsis))
+}
+
+// truncateLeft keeps the rightmost cells of s that fit in width
+func truncateLeft(s string, width int) string {
+	runes := []rune(s)
+	used := 0
+	start := len(runes)
+	for start > 0 {
+		w := StringWidth(string(runes[start-1]))
+		if used+w > width {
+			break
+		}
+		used += w
+		start--
+	}
+	return string(runes[start:])
+}
@@ -0,0 +1,128 @@
+// Package main provides tests for the directory path segment
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayPath(t *testing.T) {
+	cfg := defaultPathConfig()
+	cfg.MaxWidth = 0
+
+	tests := []struct {
+		name     string
+		project  string
+		current  string
+		style    string
+		expected string
+	}{
+		{name: "project root", project: "/home/me/code/app", current: "/home/me/code/app", expected: "app"},
+		{name: "inside project", project: "/home/me/code/app", current: "/home/me/code/app/src/ui", expected: "app/src/ui"},
+		{name: "outside project", project: "/home/me/code/app", current: "/home/me/notes", expected: "~/notes"},
+		{name: "sibling prefix is not inside", project: "/home/me/app", current: "/home/me/app2", expected: "~/app2"},
+		{name: "full style", project: "/home/me/code/app", current: "/home/me/code/app/src", style: pathStyleFull, expected: "~/code/app/src"},
+		{name: "home itself", current: "/home/me", expected: "~"},
+		{name: "outside home", current: "/srv/data", expected: "/srv/data"},
+		{name: "project only", project: "/home/me/code/app", expected: "app"},
+		{name: "trailing slash", project: "/home/me/code/app/", current: "/home/me/code/app/", expected: "app"},
+		{name: "windows separators", project: `C:\Users\me\app`, current: `C:\Users\me\app\src`, expected: `app\src`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			if tt.style != "" {
+				c.Style = tt.style
+			}
+			assert.Equal(t, tt.expected, displayPath(tt.project, tt.current, "/home/me", c))
+		})
+	}
+}
+
+func TestDisplayPath_WindowsHome(t *testing.T) {
+	cfg := defaultPathConfig()
+	cfg.Style = pathStyleFull
+	assert.Equal(t, `~\code`, displayPath("", `C:\Users\me\code`, `C:\Users\me`, cfg))
+}
+
+func TestElidePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		max      int
+		expected string
+	}{
+		{name: "fits", path: "~/a/b", max: 10, expected: "~/a/b"},
+		{name: "middle elided", path: "~/code/clients/project/src/ui", max: 20, expected: "~/…/project/src/ui"},
+		{name: "only last fits", path: "~/code/clients/project", max: 12, expected: "~/…/project"},
+		{name: "last too long", path: "~/averyveryverylongdirectory", max: 10, expected: "…directory"},
+		{name: "wide characters", path: "~/项目/文档/设计稿", max: 15, expected: "~/…/文档/设计稿"},
+		{name: "disabled", path: "~/a/b/c/d/e/f", max: 0, expected: "~/a/b/c/d/e/f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := elidePath(tt.path, tt.max)
+			assert.Equal(t, tt.expected, got)
+			if tt.max > 0 {
+				assert.LessOrEqual(t, StringWidth(got), tt.max)
+			}
+		})
+	}
+}
+
+func TestDisplayPath_Redaction(t *testing.T) {
+	cfg := defaultPathConfig()
+	cfg.Style = pathStyleFull
+	cfg.MaxWidth = 0
+	cfg.Redact = []string{`(?i)acme[-\w]*`, `client-\d+`}
+
+	got := displayPath("", "/home/me/work/ACME-corp/client-42/src", "/home/me", cfg)
+	assert.Equal(t, "~/work/***/***/src", got)
+
+	cfg.RedactWith = "[hidden]"
+	got = displayPath("/home/me/work/acme", "/home/me/work/acme/src", "/home/me", cfg)
+	assert.Equal(t, "~/work/[hidden]/src", got)
+}
+
+func TestDisplayPath_RedactsAbsolutePath(t *testing.T) {
+	cfg := defaultPathConfig()
+	cfg.MaxWidth = 0
+	cfg.Redact = []string{"/clients/acme"}
+
+	// The relative display never contains the pattern, the real path does
+	got := displayPath("/home/me/clients/acme", "/home/me/clients/acme/src", "/home/me", cfg)
+	assert.Equal(t, "***/src", got)
+
+	cfg.Style = pathStyleFull
+	cfg.Redact = []string{`^/home/me/clients/[^/]+`}
+	got = displayPath("", "/home/me/clients/acme/src", "/home/me", cfg)
+	assert.Equal(t, "***/src", got, "a redacted home prefix is not abbreviated")
+
+	cfg.Redact = []string{`^C:/Users/me/clients/[^/]+`}
+	got = displayPath("", `C:\Users\me\clients\acme\src`, `C:\Users\me`, cfg)
+	assert.Equal(t, `***\src`, got, "patterns see Windows paths with / separators")
+}
+
+func TestDisplayPath_RedactBeforeElide(t *testing.T) {
+	cfg := defaultPathConfig()
+	cfg.MaxWidth = 12
+	cfg.Redact = []string{"secretclientname"}
+
+	got := displayPath("/p/secretclientname", "/p/secretclientname", "/home/me", cfg)
+	assert.Equal(t, "***", got, "a redacted name must never be partially visible")
+}
+
+func TestPathConfig_Validate(t *testing.T) {
+	cfg := defaultPathConfig()
+	assert.NoError(t, cfg.validate())
+
+	cfg.Redact = []string{"("}
+	assert.Error(t, cfg.validate())
+
+	cfg = defaultPathConfig()
+	cfg.Style = "weird"
+	assert.Error(t, cfg.validate())
+}
@@ -0,0 +1,84 @@
+// Package main provides the OSC 9;4 progress indicator, which several
+// terminals show as a progress bar in the tab or taskbar
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+)
+
+// Progress metrics
+const (
+	progressOff       = "off"        // Send nothing
+	progressContext   = "context"    // Context window percent used
+	progressRateLimit = "rate_limit" // Rate limit percent used
+)
+
+// OSC 9;4 progress states
+const (
+	progressClear   = 0 // Remove the progress bar
+	progressNormal  = 1
+	progressError   = 2
+	progressWarning = 4 // Shown as paused, usually yellow
+)
+
+// validateProgress rejects an unknown progress metric
+func validateProgress(metric string) error {
+	switch metric {
+	case progressOff, progressContext, progressRateLimit:
+		return nil
+	}
+	return fmt.Errorf("invalid progress %q: want off, context or rate_limit", metric)
+}
+
+// progressValue returns the state and percent of a metric for a payload
+// The state follows the most severe alert rule firing on the same metric,
+// and the bar is cleared when the metric is unknown
+func progressValue(metric string, rules []alertRule, input *StatusLineInput) (state, percent int) {
+	m := newAlertMetrics(input, 0)
+	var ratio float64
+	switch metric {
+	case progressContext:
+		if m.Context.WindowSize == 0 {
+			return progressClear, 0
+		}
+		ratio = m.Context.Ratio
+	case progressRateLimit:
+		if m.Rate.Limit == 0 {
+			return progressClear, 0
+		}
+		ratio = 1 - m.Rate.Ratio
+	default:
+		return progressClear, 0
+	}
+
+	level := ""
+	for _, r := range rules {
+		if r.Metric == metric && r.firing(m) && levelRank(r.Level) > levelRank(level) {
+			level = r.Level
+		}
+	}
+	state = progressNormal
+	switch level {
+	case levelWarning:
+		state = progressWarning
+	case levelError:
+		state = progressError
+	}
+	return state, int(math.Round(min(max(ratio, 0), 1) * 100))
+}
+
+// progressSequence returns the OSC 9;4 sequence for a metric
+func progressSequence(metric string, rules []alertRule, input *StatusLineInput) string {
+	if metric == progressOff || metric == "" {
+		return ""
+	}
+	state, percent := progressValue(metric, rules, input)
+	return fmt.Sprintf("\x1b]9;4;%d;%d\a", state, percent)
+}
+
+// emitProgress sends the progress indicator to the terminal
+func emitProgress(cfg Config, input *StatusLineInput, debugFile *os.File) {
+	writeTTY(progressSequence(cfg.Progress, cfg.Alerts.Rules, input), debugFile)
+}
@@ -0,0 +1,53 @@
+// Package main provides tests for the OSC 9;4 progress indicator
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressValue_Context(t *testing.T) {
+	rules := defaultAlertRules()
+	tests := []struct {
+		percent int
+		state   int
+	}{
+		{percent: 42, state: progressNormal},
+		{percent: 85, state: progressWarning},
+		{percent: 97, state: progressError},
+	}
+	for _, tt := range tests {
+		state, percent := progressValue(progressContext, rules, testPayload{Used: tt.percent, Window: 100}.build())
+		assert.Equal(t, tt.state, state, "%d%%", tt.percent)
+		assert.Equal(t, tt.percent, percent)
+	}
+
+	state, _ := progressValue(progressContext, rules, &StatusLineInput{})
+	assert.Equal(t, progressClear, state, "unknown window clears the bar")
+}
+
+func TestProgressValue_RateLimit(t *testing.T) {
+	input := &StatusLineInput{}
+	input.RateLimit.Limit = 1000
+	input.RateLimit.Remaining = 50
+
+	state, percent := progressValue(progressRateLimit, defaultAlertRules(), input)
+	assert.Equal(t, progressWarning, state)
+	assert.Equal(t, 95, percent, "the bar fills as the limit drains")
+}
+
+func TestProgressSequence(t *testing.T) {
+	assert.Empty(t, progressSequence(progressOff, nil, testPayload{Used: 50, Window: 100}.build()))
+	assert.Equal(t, "\x1b]9;4;1;50\a", progressSequence(progressContext, nil, testPayload{Used: 50, Window: 100}.build()))
+}
+
+func TestParseArgs_Progress(t *testing.T) {
+	opts, err := parseArgs([]string{"--progress", "rate_limit"})
+	require.NoError(t, err)
+	assert.Equal(t, progressRateLimit, opts.Progress)
+
+	_, err = parseArgs([]string{"--progress", "cpu"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+// Package main provides "statusline replay", which plays back a capture
+// file through the renderer to reproduce a real session offline
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// replayTick is the redraw interval, the same as --animate
+const replayTick = 100 * time.Millisecond
+
+// replayIndex returns the record shown at the replay clock: the last one
+// that had arrived by then, or -1 before the first
+func replayIndex(records []captureRecord, clock time.Time) int {
+	index := -1
+	for i, rec := range records {
+		if rec.Time.After(clock) {
+			break
+		}
+		index = i
+	}
+	return index
+}
+
+// replayClock maps wall time elapsed since the replay started onto the
+// capture timeline, scaled by speed
+func replayClock(start time.Time, elapsed time.Duration, speed float64) time.Time {
+	return start.Add(time.Duration(float64(elapsed) * speed))
+}
+
+// replayScreen renders one screen of the replay: a progress line and the
+// statusline as it was rendered for the record, drawn at the replay clock
+// The title names the source, such as replay or simulate
+func replayScreen(title string, records []captureRecord, index int, session *sessionState, clock time.Time, speed float64, opts options, cfg Config) string {
+	var screen strings.Builder
+	screen.WriteString(colorClear)
+
+	rec := records[index]
+	fmt.Fprintf(&screen, "%s▶ %s %d/%d · %s · %gx%s\n\n",
+		colorRed160, title, index+1, len(records), rec.Time.Local().Format("2006-01-02 15:04:05.000"), speed, colorReset)
+
+	opts.At = clock
+	var buf bytes.Buffer
+	safeRender(&buf, nil, func(w io.Writer) {
+		writeStatusLine(w, decodeCapture(rec), session, nil, opts, cfg)
+	})
+	screen.Write(buf.Bytes())
+	return screen.String()
+}
+
+// decodeCapture decodes a captured payload like main does, so odd
+// payloads render the same way; nil means an empty payload
+func decodeCapture(rec captureRecord) *StatusLineInput {
+	payload := trimNullBytes([]byte(rec.Payload))
+	if len(payload) == 0 {
+		return nil
+	}
+	input := &StatusLineInput{}
+	_ = json.Unmarshal(payload, input)
+	return input
+}
+
+// runReplayCommand handles "statusline replay <capture>"
+func runReplayCommand(opts options, cfg Config) error {
+	if len(opts.Args) < 2 {
+		return fmt.Errorf("replay: missing capture file")
+	}
+	f, err := os.Open(opts.Args[1])
+	if err != nil {
+		return err
+	}
+	records, err := readCaptures(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("replay: %s has no captures", opts.Args[1])
+	}
+	playCaptures("replay", records, opts, cfg)
+	return nil
+}
+
+// playCaptures shows records in an animate-style view, switching payloads
+// at their original pace scaled by --speed, and returns after the last one
+func playCaptures(title string, records []captureRecord, opts options, cfg Config) {
+	speed := opts.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	start := records[0].Time
+	end := records[len(records)-1].Time
+
+	ticker := time.NewTicker(replayTick)
+	defer ticker.Stop()
+
+	// The session lives in memory: every record advances it in order, even
+	// those skipped over at high speed, so delta based segments match
+	session := sessionState{Version: stateSchemaVersion, Key: title}
+	shown := -1
+
+	began := time.Now()
+	for {
+		clock := replayClock(start, time.Since(began), speed)
+		if clock.After(end) {
+			clock = end
+		}
+		index := replayIndex(records, clock)
+		for shown < index {
+			shown++
+			updateSessionState(&session, decodeCapture(records[shown]), records[shown].Time, cfg.Alerts.Rules)
+		}
+		fmt.Print(replayScreen(title, records, index, &session, clock, speed, opts, cfg))
+		if !clock.Before(end) {
+			return
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,45 @@
+// Package main provides tests for the "statusline replay" subcommand
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayIndex(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	records := []captureRecord{{Time: t0}, {Time: t0.Add(2 * time.Second)}, {Time: t0.Add(5 * time.Second)}}
+
+	assert.Equal(t, -1, replayIndex(records, t0.Add(-time.Millisecond)))
+	assert.Equal(t, 0, replayIndex(records, t0))
+	assert.Equal(t, 1, replayIndex(records, t0.Add(4999*time.Millisecond)))
+	assert.Equal(t, 2, replayIndex(records, t0.Add(time.Minute)))
+}
+
+func TestReplayClock_Scaled(t *testing.T) {
+	t0 := time.UnixMilli(0)
+	assert.Equal(t, t0.Add(3*time.Second), replayClock(t0, 1500*time.Millisecond, 2))
+	assert.Equal(t, t0.Add(500*time.Millisecond), replayClock(t0, time.Second, 0.5))
+}
+
+func TestReplayScreen(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	records := []captureRecord{
+		{Time: t0, Payload: `{"model": {"display_name": "Opus"}}`},
+		{Time: t0.Add(time.Second), Payload: `{"model": `},
+	}
+	opts, _ := parseArgs(nil)
+	cfg := defaultConfig()
+	cfg.Layout = layoutFull
+	cfg.Segments = []string{"model"}
+
+	screen := replayScreen("replay", records, 0, nil, t0, 2, opts, cfg)
+	assert.Contains(t, screen, "replay 1/2")
+	assert.Contains(t, screen, "2x")
+	assert.Contains(t, screen, "Opus")
+
+	broken := replayScreen("replay", records, 1, nil, t0.Add(time.Second), 1, opts, cfg)
+	assert.Contains(t, broken, "replay 2/2", "an unparsable payload still renders")
+}
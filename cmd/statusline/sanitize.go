@@ -0,0 +1,84 @@
+// Package main provides sanitizing of untrusted text before rendering
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// sanitizeText makes input-derived text safe to print in a terminal.
+// Payload fields and transcripts are untrusted: an ESC, BEL or OSC
+// sequence in a directory name must never reach the terminal.
+//
+//   - Tabs, newlines and line/paragraph separators become spaces
+//   - Other C0 controls and DEL are escaped in caret notation (ESC -> ^[)
+//   - C1 controls, bidi overrides/isolates, BOM and tag characters are removed
+//   - Invalid UTF-8 becomes U+FFFD
+func sanitizeText(s string) string {
+	if isSafeText(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteRune(utf8.RuneError)
+		case r == '\t' || r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029':
+			b.WriteByte(' ')
+		case r < 0x20:
+			b.WriteByte('^')
+			b.WriteByte(byte(r) + '@')
+		case r == 0x7f:
+			b.WriteString("^?")
+		case isStrippedRune(r):
+			// Dropped entirely
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isSafeText reports whether s needs no sanitizing (the common case)
+func isSafeText(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return false
+		}
+		if isUnsafeRune(r) {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+// isUnsafeRune reports whether a rune is changed by sanitizeText
+func isUnsafeRune(r rune) bool {
+	return r < 0x20 || r == 0x7f || r == '\u2028' || r == '\u2029' || isStrippedRune(r)
+}
+
+// isStrippedRune reports whether a rune is removed by sanitizeText
+func isStrippedRune(r rune) bool {
+	switch {
+	case r >= 0x80 && r <= 0x9f: // C1 controls, including 8-bit CSI and OSC
+		return true
+	case r == '\u061c', r == '\u200e', r == '\u200f': // Bidi marks
+		return true
+	case r >= '\u202a' && r <= '\u202e': // Bidi embeddings and overrides
+		return true
+	case r >= '\u2066' && r <= '\u2069': // Bidi isolates
+		return true
+	case r == '\ufeff': // Byte order mark / zero width no-break space
+		return true
+	case r >= 0xe0000 && r <= 0xe007f: // Invisible tag characters
+		return true
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// Package main provides tests and fuzz tests for text sanitizing
+package main
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain text unchanged", input: "claude-ride ⎇ main 🐴", expected: "claude-ride ⎇ main 🐴"},
+		{name: "CJK unchanged", input: "马到成功", expected: "马到成功"},
+		{name: "CSI color escape", input: "a\x1b[31mred", expected: "a^[[31mred"},
+		{name: "OSC title with BEL", input: "\x1b]0;pwned\x07x", expected: "^[]0;pwned^Gx"},
+		{name: "newlines and tabs", input: "a\nb\tc\rd", expected: "a b c d"},
+		{name: "DEL", input: "a\x7fb", expected: "a^?b"},
+		{name: "C1 CSI", input: "a\u009b31mb", expected: "a31mb"},
+		{name: "bidi override", input: "file\u202egpj.exe", expected: "filegpj.exe"},
+		{name: "bidi isolate", input: "\u2066x\u2069", expected: "x"},
+		{name: "line separator", input: "a\u2028b", expected: "a b"},
+		{name: "invalid UTF-8", input: "a\xffb", expected: "a�b"},
+		{name: "tag characters", input: "ok\U000E0041", expected: "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sanitizeText(tt.input))
+		})
+	}
+}
+
+func TestBuildSegments_Sanitized(t *testing.T) {
+	ctx := &segmentContext{
+		Status: statusModel{Model: modelInfo{DisplayName: "Opus\x1b]0;evil\x07"}},
+		Config: Config{Segments: []string{"model"}},
+	}
+
+	segs := buildSegments(ctx)
+	assert.Equal(t, "Opus^[]0;evil^G", segs[0].Text)
+}
+
+func FuzzSanitizeText(f *testing.F) {
+	seeds := []string{
+		"",
+		"plain",
+		"\x1b[2J\x1b[H",
+		"\x1b]8;;file:///etc/passwd\x1b\\link\x1b]8;;\x1b\\",
+		"\u202eevil",
+		"\xc0\xaf",
+		"🐴⏜))~ ﾉﾉ",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		out := sanitizeText(input)
+
+		if !utf8.ValidString(out) {
+			t.Fatalf("output is not valid UTF-8: %q", out)
+		}
+		for _, r := range out {
+			if isUnsafeRune(r) {
+				t.Fatalf("unsafe rune %U survived in %q (input %q)", r, out, input)
+			}
+		}
+		if again := sanitizeText(out); again != out {
+			t.Fatalf("not idempotent: %q -> %q", out, again)
+		}
+	})
+}
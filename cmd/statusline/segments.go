@@ -0,0 +1,273 @@
+// Package main provides the status segments shown next to the horse
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// segmentSeparator separates segments on the output line
+const segmentSeparator = " · "
+
+// segmentPlaceholder replaces the text of a segment that failed
+const segmentPlaceholder = "⚠"
+
+// segment is one piece of status text, such as the model or context usage
+type segment struct {
+	Name  string
+	Text  string
+	Color int    // xterm 256-color index or colorDefault for the theme color
+	Link  string // Local path the segment links to, see hyperlink.go
+}
+
+// segmentContext is the data available to segment builders
+type segmentContext struct {
+	Input  *StatusLineInput // nil when no payload arrived
+	Status statusModel
+	Config Config
+
+	Session *sessionState // Stored state of this session, nil when stateless
+	Now     time.Time
+
+	DebugFile *os.File // Segment failures are logged here, if enabled
+}
+
+// segmentFunc builds a segment; an empty text hides the segment
+type segmentFunc func(ctx *segmentContext) segment
+
+// segmentRegistry maps config names to segment builders
+var segmentRegistry = map[string]segmentFunc{
+	"path":        pathSegment,
+	"model":       modelSegment,
+	"context":     contextSegment,
+	"cost":        costSegment,
+	"git":         gitSegment,
+	"rate_limit":  rateLimitSegment,
+	"lines":       linesSegment,
+	"duration":    durationSegment,
+	"version":     versionSegment,
+	"style":       outputStyleSegment,
+	"throughput":  throughputSegment,
+	"eta":         forecastSegment,
+	"compactions": compactionSegment,
+	"transcript":  transcriptSegment,
+}
+
+// customSegmentConfig defines a segment from a Go text/template
+// The template receives templateData, including unknown payload fields
+type customSegmentConfig struct {
+	Template string `json:"template"`
+}
+
+// templateData is the data passed to custom segment templates
+type templateData struct {
+	Input  *StatusLineInput // Decoded payload, nil when none arrived
+	Status statusModel      // Computed status
+	Extra  map[string]any   // Payload fields not modeled by StatusLineInput
+}
+
+// buildSegments builds the enabled segments in config order
+// Every segment text passes through sanitizeText here, so individual
+// segments never print raw input-derived strings; empty segments are dropped
+func buildSegments(ctx *segmentContext) []segment {
+	var segs []segment
+	links := hyperlinksEnabled(ctx.Config.Hyperlinks)
+	for _, name := range ctx.Config.Segments {
+		build, ok := lookupSegment(name, ctx.Config)
+		if !ok {
+			continue
+		}
+		seg := safeSegment(name, build, ctx)
+		seg.Text = sanitizeText(seg.Text)
+		if seg.Text == "" {
+			continue
+		}
+		// Links wrap the sanitized text, so they never affect its width
+		if seg.Link != "" && links {
+			seg.Link = fileURI(seg.Link)
+		} else {
+			seg.Link = ""
+		}
+		seg.Name = name
+		segs = append(segs, seg)
+	}
+	return segs
+}
+
+// lookupSegment finds a built-in segment or a custom template segment
+// Built-in names take precedence over custom ones
+func lookupSegment(name string, cfg Config) (segmentFunc, bool) {
+	if build, ok := segmentRegistry[name]; ok {
+		return build, true
+	}
+	if custom, ok := cfg.CustomSegments[name]; ok {
+		return templateSegment(custom.Template), true
+	}
+	return nil, false
+}
+
+// parseSegmentTemplate parses a custom segment template
+func parseSegmentTemplate(text string) (*template.Template, error) {
+	return template.New("segment").Option("missingkey=zero").Parse(text)
+}
+
+// templateSegment returns a segment builder that executes a template
+// Execution errors leave the segment empty and are logged
+func templateSegment(text string) segmentFunc {
+	return func(ctx *segmentContext) segment {
+		tpl, err := parseSegmentTemplate(text)
+		if err != nil {
+			debugLog(ctx.DebugFile, "segment template: %v", err)
+			return segment{Color: colorDefault}
+		}
+
+		var b strings.Builder
+		data := templateData{Input: ctx.Input, Status: ctx.Status, Extra: ctx.Input.extraValues()}
+		if err := tpl.Execute(&b, data); err != nil {
+			debugLog(ctx.DebugFile, "segment template: %v", err)
+			return segment{Color: colorDefault}
+		}
+		return segment{Text: strings.TrimSpace(b.String()), Color: colorDefault}
+	}
+}
+
+// safeSegment runs a segment builder so that a panic only affects that
+// segment: it is replaced by a placeholder and the error is logged
+func safeSegment(name string, build segmentFunc, ctx *segmentContext) (seg segment) {
+	defer func() {
+		if r := recover(); r != nil {
+			debugLog(ctx.DebugFile, "segment %s panic: %v\n%s", name, r, debug.Stack())
+			seg = segment{Text: segmentPlaceholder + " " + name, Color: 214}
+		}
+	}()
+	return build(ctx)
+}
+
+// segmentLine joins segments into one styled line
+func segmentLine(segs []segment, theme Theme) styledLine {
+	var line styledLine
+	for i, seg := range segs {
+		if i > 0 {
+			line = append(line, styledRun{Text: segmentSeparator, Color: theme.Track})
+		}
+		c := seg.Color
+		if c == colorDefault {
+			c = theme.Segment
+		}
+		line = append(line, styledRun{Text: seg.Text, Color: c, Link: seg.Link})
+	}
+	return line
+}
+
+// modelSegment shows the model display name
+func modelSegment(ctx *segmentContext) segment {
+	name := ctx.Status.Model.DisplayName
+	if name == "" {
+		name = ctx.Status.Model.ID
+	}
+	return segment{Text: name, Color: colorDefault}
+}
+
+// contextSegment shows context window usage, colored by alert level
+func contextSegment(ctx *segmentContext) segment {
+	c := ctx.Status.Context
+	if c.WindowSize == 0 {
+		return segment{Color: colorDefault}
+	}
+
+	color := colorDefault
+	switch {
+	case c.Ratio >= contextErrorRatio:
+		color = 196 // Red
+	case c.Ratio >= contextWarningRatio:
+		color = 214 // Orange
+	}
+	return segment{Text: fmt.Sprintf("ctx %.0f%%", c.Ratio*100), Color: color}
+}
+
+// costSegment shows the session cost in USD
+func costSegment(ctx *segmentContext) segment {
+	if ctx.Status.Cost.TotalUSD <= 0 {
+		return segment{Color: colorDefault}
+	}
+	return segment{Text: fmt.Sprintf("$%.2f", ctx.Status.Cost.TotalUSD), Color: colorDefault}
+}
+
+// gitSegment shows the branch, or the short commit when detached
+func gitSegment(ctx *segmentContext) segment {
+	g := ctx.Status.Git
+	if g == nil {
+		return segment{Color: colorDefault}
+	}
+	name := g.Branch
+	if name == "" && len(g.Commit) >= 7 {
+		name = g.Commit[:7]
+	}
+	if name == "" {
+		return segment{Color: colorDefault}
+	}
+	return segment{Text: "⎇ " + name, Color: colorDefault}
+}
+
+// rateLimitSegment shows the remaining rate limit
+func rateLimitSegment(ctx *segmentContext) segment {
+	r := ctx.Status.RateLimit
+	if r.Limit == 0 {
+		return segment{Color: colorDefault}
+	}
+	color := colorDefault
+	if r.Ratio <= rateLimitLowRatio {
+		color = 196
+	}
+	return segment{Text: fmt.Sprintf("rl %d/%d", r.Remaining, r.Limit), Color: color}
+}
+
+// linesSegment shows lines added and removed in the session
+func linesSegment(ctx *segmentContext) segment {
+	c := ctx.Status.Cost
+	if c.LinesAdded == 0 && c.LinesRemoved == 0 {
+		return segment{Color: colorDefault}
+	}
+	return segment{Text: fmt.Sprintf("+%d -%d", c.LinesAdded, c.LinesRemoved), Color: colorDefault}
+}
+
+// durationSegment shows the session duration
+func durationSegment(ctx *segmentContext) segment {
+	ms := ctx.Status.Cost.DurationMS
+	if ms <= 0 {
+		return segment{Color: colorDefault}
+	}
+	return segment{Text: formatDuration(time.Duration(ms) * time.Millisecond), Color: colorDefault}
+}
+
+// versionSegment shows the Claude Code version
+func versionSegment(ctx *segmentContext) segment {
+	if ctx.Status.Version == "" {
+		return segment{Color: colorDefault}
+	}
+	return segment{Text: "v" + ctx.Status.Version, Color: colorDefault}
+}
+
+// outputStyleSegment shows the active output style unless it is the default
+func outputStyleSegment(ctx *segmentContext) segment {
+	if ctx.Status.OutputStyle == "" || ctx.Status.OutputStyle == "default" {
+		return segment{Color: colorDefault}
+	}
+	return segment{Text: ctx.Status.OutputStyle, Color: colorDefault}
+}
+
+// formatDuration formats a duration compactly: 45s, 12m, 1h05m
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,123 @@
+// Package main provides tests for status segments
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSegments_OrderAndEmpty(t *testing.T) {
+	ctx := &segmentContext{
+		Status: statusModel{
+			Model:   modelInfo{DisplayName: "Opus"},
+			Context: contextInfo{UsedTokens: 50, WindowSize: 100, Ratio: 0.5},
+		},
+		Config: Config{Segments: []string{"context", "cost", "model"}},
+	}
+
+	segs := buildSegments(ctx)
+
+	// Cost is zero and therefore hidden; config order is kept
+	assert.Equal(t, []segment{
+		{Name: "context", Text: "ctx 50%", Color: colorDefault},
+		{Name: "model", Text: "Opus", Color: colorDefault},
+	}, segs)
+}
+
+func TestContextSegment_Colors(t *testing.T) {
+	tests := []struct {
+		ratio    float64
+		expected int
+	}{
+		{ratio: 0.5, expected: colorDefault},
+		{ratio: 0.85, expected: 214},
+		{ratio: 0.97, expected: 196},
+	}
+
+	for _, tt := range tests {
+		ctx := &segmentContext{Status: statusModel{Context: contextInfo{WindowSize: 100, Ratio: tt.ratio}}}
+		assert.Equal(t, tt.expected, contextSegment(ctx).Color, "ratio %.2f", tt.ratio)
+	}
+}
+
+func TestGitSegment_DetachedShowsShortCommit(t *testing.T) {
+	ctx := &segmentContext{Status: statusModel{Git: &gitInfo{Commit: "0123456789abcdef"}}}
+	assert.Equal(t, "⎇ 0123456", gitSegment(ctx).Text)
+}
+
+func TestSegmentLine(t *testing.T) {
+	line := segmentLine([]segment{
+		{Text: "Opus", Color: colorDefault},
+		{Text: "ctx 96%", Color: 196},
+	}, defaultTheme)
+
+	assert.Equal(t, "Opus · ctx 96%", line.plain())
+	assert.Equal(t, 196, line[2].Color)
+}
+
+func TestBuildSegments_PanicIsIsolated(t *testing.T) {
+	segmentRegistry["broken"] = func(*segmentContext) segment {
+		var m map[string]int
+		m["boom"]++ // nil map write panics
+		return segment{}
+	}
+	defer delete(segmentRegistry, "broken")
+
+	ctx := &segmentContext{
+		Status: statusModel{Model: modelInfo{DisplayName: "Opus"}},
+		Config: Config{Segments: []string{"broken", "model"}},
+	}
+
+	segs := buildSegments(ctx)
+	assert.Len(t, segs, 2, "the failing segment must not take others down")
+	assert.Equal(t, segmentPlaceholder+" broken", segs[0].Text)
+	assert.Equal(t, "Opus", segs[1].Text)
+}
+
+func TestTemplateSegment_ExtraFields(t *testing.T) {
+	var input StatusLineInput
+	require.NoError(t, json.Unmarshal([]byte(`{"agent": "reviewer", "cost": {"total_lines_added": 5}}`), &input))
+
+	cfg := Config{
+		Segments: []string{"who"},
+		CustomSegments: map[string]customSegmentConfig{
+			"who": {Template: `{{.Extra.agent}} +{{.Input.Cost.TotalLinesAdded}}`},
+		},
+	}
+	ctx := &segmentContext{Input: &input, Config: cfg}
+
+	segs := buildSegments(ctx)
+	require.Len(t, segs, 1)
+	assert.Equal(t, "reviewer +5", segs[0].Text)
+}
+
+func TestTemplateSegment_MissingExtraIsEmpty(t *testing.T) {
+	build := templateSegment(`{{with .Extra.agent}}{{.}}{{end}}`)
+	assert.Empty(t, build(&segmentContext{}).Text, "nil input and missing keys render nothing")
+}
+
+func TestConfigValidate_CustomSegments(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.CustomSegments = map[string]customSegmentConfig{"mine": {Template: "{{.Status.Model.ID}}"}}
+	cfg.Segments = []string{"mine"}
+	assert.NoError(t, cfg.validate())
+
+	cfg.CustomSegments["mine"] = customSegmentConfig{Template: "{{.Broken"}
+	assert.Error(t, cfg.validate())
+}
+
+func TestNewPayloadSegments(t *testing.T) {
+	ctx := &segmentContext{Status: statusModel{
+		Version:     "1.0.80",
+		OutputStyle: "Explanatory",
+		Cost:        costInfo{DurationMS: 3_900_000, LinesAdded: 156, LinesRemoved: 23},
+	}}
+
+	assert.Equal(t, "+156 -23", linesSegment(ctx).Text)
+	assert.Equal(t, "1h05m", durationSegment(ctx).Text)
+	assert.Equal(t, "v1.0.80", versionSegment(ctx).Text)
+	assert.Equal(t, "Explanatory", outputStyleSegment(ctx).Text)
+}
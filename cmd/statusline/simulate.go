@@ -0,0 +1,145 @@
+// Package main provides "statusline simulate", a synthetic session for
+// previewing data-driven behavior without spending real tokens
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Shape of the simulated session
+const (
+	simulateSteps       = 60              // Payloads in the session
+	simulateStep        = 2 * time.Second // Time between payloads
+	simulateWindow      = 200_000         // Context window size
+	simulateCompactStep = 45              // Payload right after the compaction
+	simulateSwitchStep  = 30              // First payload on the second model
+	simulateStallStep   = 20              // First payload of a stretch without output
+	simulateRateLimit   = 1000            // Requests allowed in the window
+)
+
+// simulateSegments is the preview shown when the config enables no
+// segments, one for every state the session walks through
+var simulateSegments = []string{"model", "context", "cost", "rate_limit", "lines", "throughput", "eta", "compactions"}
+
+// simulateSession returns a fake but realistic session of payloads: the
+// context grows through the warning and error thresholds and is then
+// compacted, tool calls add lines and API time, output stalls and bursts,
+// the rate limit drains below its low mark and the model is switched
+// halfway through
+func simulateSession(cwd string) []StatusLineInput {
+	inputs := make([]StatusLineInput, simulateSteps)
+
+	var (
+		used     = 20_000
+		totalIn  int
+		totalOut int
+		cost     float64
+		apiMS    int64
+		added    int
+		removed  int
+	)
+	for i := range inputs {
+		in := &inputs[i]
+		in.HookEventName = "Status"
+		in.SessionID = "simulated-session"
+		in.Version = "1.0.80"
+		in.TranscriptPath = filepath.Join(os.TempDir(), "simulated-session.jsonl")
+		in.Cwd = cwd
+		in.Workspace.CurrentDir = cwd
+		in.Workspace.ProjectDir = cwd
+		in.OutputStyle.Name = "default"
+
+		// Model switch: Opus first, then Sonnet at a third of the price
+		price := 0.03
+		in.Model.ID, in.Model.DisplayName = "claude-opus-4-1", "Opus"
+		if i >= simulateSwitchStep {
+			price = 0.01
+			in.Model.ID, in.Model.DisplayName = "claude-sonnet-4-5", "Sonnet"
+		}
+
+		// Context grows to 96% of the window, then compaction drops it
+		switch {
+		case i == simulateCompactStep:
+			used = 28_000
+		case i > 0 && i < simulateCompactStep:
+			used += (simulateWindow*96/100 - 20_000) / (simulateCompactStep - 1)
+		case i > simulateCompactStep:
+			used += 3_000
+		}
+
+		// Every third payload follows a tool call that edits files; a long
+		// running tool stalls output for a while, so the horse walks
+		output := 120
+		switch {
+		case i >= simulateStallStep && i < simulateStallStep+6:
+			output = 0
+		case i%3 == 0 && i > 0:
+			output = 400
+			added += 5 + i%7
+			removed += i % 4
+			apiMS += 1_800
+		}
+		apiMS += 600
+		totalIn += used
+		if i == simulateCompactStep {
+			totalIn = used // Compaction starts the input total over
+		}
+		totalOut += output
+		cost += price
+
+		in.ContextWindow.ContextWindowSize = simulateWindow
+		in.ContextWindow.TotalInputTokens = totalIn
+		in.ContextWindow.TotalOutputTokens = totalOut
+		in.ContextWindow.CurrentUsage.InputTokens = used / 10
+		in.ContextWindow.CurrentUsage.CacheReadInputTokens = used - used/10
+		in.ContextWindow.CurrentUsage.OutputTokens = output
+
+		in.RateLimit.Limit = simulateRateLimit
+		in.RateLimit.Remaining = max(0, simulateRateLimit-i*17)
+
+		in.Cost.TotalCostUSD = cost
+		in.Cost.TotalDurationMS = int64(i) * simulateStep.Milliseconds()
+		in.Cost.TotalAPIDurationMS = apiMS
+		in.Cost.TotalLinesAdded = added
+		in.Cost.TotalLinesRemoved = removed
+	}
+	return inputs
+}
+
+// simulateCaptures turns the simulated session into capture records
+// starting at start, so it plays through the replay view
+func simulateCaptures(start time.Time, cwd string) []captureRecord {
+	inputs := simulateSession(cwd)
+	records := make([]captureRecord, len(inputs))
+	for i, in := range inputs {
+		payload, _ := json.Marshal(in)
+		records[i] = captureRecord{Time: start.Add(time.Duration(i) * simulateStep), Payload: string(payload)}
+	}
+	return records
+}
+
+// runSimulateCommand handles "statusline simulate"
+func runSimulateCommand(opts options, cfg Config) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	start := opts.At
+	if start.IsZero() {
+		start = time.Now()
+	}
+	playCaptures("simulate", simulateCaptures(start, cwd), opts, simulateConfig(cfg))
+	return nil
+}
+
+// simulateConfig returns cfg with the preview segments when it has none,
+// since the bare horse would hide every state the session shows
+func simulateConfig(cfg Config) Config {
+	if len(cfg.Segments) == 0 {
+		cfg.Segments = simulateSegments
+	}
+	return cfg
+}
@@ -0,0 +1,76 @@
+// Package main provides tests for the "statusline simulate" synthetic session
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateSession_CoversEveryState(t *testing.T) {
+	inputs := simulateSession("/tmp/project")
+	require.Len(t, inputs, simulateSteps)
+
+	var warned, errored, rateLow bool
+	models := map[string]bool{}
+	for i := range inputs {
+		ctx := contextUsage(&inputs[i])
+		warned = warned || (ctx.Ratio >= contextWarningRatio && ctx.Ratio < contextErrorRatio)
+		errored = errored || ctx.Ratio >= contextErrorRatio
+		rateLow = rateLow || rateLimitUsage(&inputs[i]).Ratio <= rateLimitLowRatio
+		models[inputs[i].Model.ID] = true
+
+		if i > 0 {
+			prev := inputs[i-1]
+			assert.GreaterOrEqual(t, inputs[i].Cost.TotalLinesAdded, prev.Cost.TotalLinesAdded)
+			assert.GreaterOrEqual(t, inputs[i].Cost.TotalCostUSD, prev.Cost.TotalCostUSD)
+		}
+	}
+
+	assert.True(t, warned, "context passes the warning threshold")
+	assert.True(t, errored, "context passes the error threshold")
+	assert.True(t, rateLow, "rate limit drains below the low mark")
+	assert.Len(t, models, 2, "the model is switched")
+
+	before := contextUsage(&inputs[simulateCompactStep-1]).UsedTokens
+	after := contextUsage(&inputs[simulateCompactStep]).UsedTokens
+	assert.Less(t, after, before/2, "compaction drops the context")
+}
+
+func TestSimulateCaptures_Decode(t *testing.T) {
+	start := time.UnixMilli(1_700_000_000_000)
+	records := simulateCaptures(start, "/tmp/project")
+	require.Len(t, records, simulateSteps)
+
+	last := records[len(records)-1]
+	assert.Equal(t, start.Add((simulateSteps-1)*simulateStep), last.Time)
+
+	var input StatusLineInput
+	require.NoError(t, json.Unmarshal([]byte(last.Payload), &input))
+	assert.Equal(t, "/tmp/project", input.Workspace.ProjectDir)
+	assert.Empty(t, input.Extra, "simulated payloads only use modeled fields")
+}
+
+func TestSimulateSession_CompactionIsDetected(t *testing.T) {
+	inputs := simulateSession("/tmp/project")
+	start := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	for i := range inputs {
+		updateSessionState(&st, &inputs[i], start.Add(time.Duration(i)*simulateStep), nil)
+	}
+	assert.Equal(t, 1, st.Compactions)
+	assert.Equal(t, start.Add(simulateCompactStep*simulateStep), st.LastCompaction.At)
+}
+
+func TestSimulateConfig_PreviewSegments(t *testing.T) {
+	cfg := simulateConfig(defaultConfig())
+	assert.Equal(t, simulateSegments, cfg.Segments, "the bare default shows every state")
+	assert.NoError(t, cfg.validate())
+
+	custom := defaultConfig()
+	custom.Segments = []string{"git"}
+	assert.Equal(t, []string{"git"}, simulateConfig(custom).Segments, "configured segments are kept")
+}
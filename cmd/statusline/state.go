@@ -0,0 +1,297 @@
+// Package main provides the per-session state store
+// Every statusline call is a separate process, so anything computed from
+// deltas between calls (throughput, idle time, alerts) is kept here
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// stateSchemaVersion is bumped whenever sessionState changes incompatibly
+// State written with another version is discarded rather than misread
+const stateSchemaVersion = 1
+
+// State store timing
+const (
+	stateLockTimeout = 200 * time.Millisecond // Give up rather than stall the statusline
+	stateMaxAge      = 7 * 24 * time.Hour     // Sessions untouched this long are removed
+	stateGCInterval  = time.Hour              // Minimum time between collections
+)
+
+// stateGCMarker is touched after every garbage collection
+const stateGCMarker = ".gc"
+
+// errLockBusy is returned by tryLockFile when another process holds the lock
+var errLockBusy = errors.New("state locked by another process")
+
+// sessionState is what the plugin remembers about one Claude Code session
+type sessionState struct {
+	Version  int       `json:"version"`
+	Key      string    `json:"key"`
+	Created  time.Time `json:"created"`
+	Calls    int       `json:"calls"`     // Statusline invocations seen
+	PrevCall time.Time `json:"prev_call"` // Invocation before the latest one
+	LastCall time.Time `json:"last_call"`
+
+	// Output throughput, see throughput.go
+	OutputTokens      int       `json:"output_tokens"`      // TotalOutputTokens at the last call
+	Throughput        float64   `json:"throughput"`         // Smoothed output tokens per second
+	ThroughputSamples int       `json:"throughput_samples"` // Measurements taken so far
+	AnimationMS       int64     `json:"animation_ms"`       // Speed-scaled animation clock at the last call
+	OutputAt          time.Time `json:"output_at"`          // When the output last grew
+
+	// Gait state machine, see gait.go
+	Gait           string    `json:"gait"`
+	GaitSince      time.Time `json:"gait_since"`       // Wall time the gait was entered
+	GaitStartMS    int64     `json:"gait_start_ms"`    // Animation clock when entered
+	GaitStartSteps float64   `json:"gait_start_steps"` // Track steps taken when entered
+
+	// Context history for the fill forecast, see forecast.go
+	ContextWindow  int             `json:"context_window"`
+	ContextHistory []contextSample `json:"context_history"`
+
+	// Compactions, see compaction.go
+	InputTokens    int             `json:"input_tokens"` // TotalInputTokens at the last call
+	Compactions    int             `json:"compactions"`
+	LastCompaction compactionEvent `json:"last_compaction"`
+
+	// Alerts, see alert.go
+	Firing   map[string]string `json:"firing"`    // Level of each alert firing at the last call
+	Raised   []alertEvent      `json:"raised"`    // Alerts raised by the last call
+	RaisedAt time.Time         `json:"raised_at"` // When an alert was last raised
+
+	// Window title, see title.go
+	Title        string `json:"title"`         // Title last sent
+	TitleChanged bool   `json:"title_changed"` // The last call changed the title
+}
+
+// idle returns the time between the last two invocations
+func (s *sessionState) idle() time.Duration {
+	if s == nil || s.PrevCall.IsZero() {
+		return 0
+	}
+	return s.LastCall.Sub(s.PrevCall)
+}
+
+// updateSessionState advances the state for a payload arriving at now
+// It is used for stored sessions as well as in-memory ones in replay
+func updateSessionState(st *sessionState, input *StatusLineInput, now time.Time, rules []alertRule) {
+	if st.Created.IsZero() {
+		st.Created = now
+	}
+	// Deltas are taken against the previous call, so they run first
+	sampleThroughput(st, input, now)
+	advanceAnimation(st, now)
+	detectCompaction(st, input, now)
+	raiseAlerts(st, input, now, rules)
+	advanceGait(st, input, now)
+	recordContext(st, input, now)
+
+	st.Calls++
+	st.PrevCall, st.LastCall = st.LastCall, now
+}
+
+// sessionKey identifies the session of a payload: the session id when it
+// is safe as a file name, otherwise a hash of the session id or transcript
+// path. An empty key means the payload cannot be tied to a session
+func sessionKey(input *StatusLineInput) string {
+	if input == nil {
+		return ""
+	}
+	if id := input.SessionID; id != "" {
+		if len(id) <= 64 && strings.Trim(id, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_") == "" {
+			return id
+		}
+		return hashKey(id)
+	}
+	if input.TranscriptPath != "" {
+		return hashKey(input.TranscriptPath)
+	}
+	return ""
+}
+
+// hashKey returns a file name safe digest of s
+func hashKey(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
+
+// stateDir returns the per-user state directory of the plugin:
+// $XDG_STATE_HOME or ~/.local/state on Unix, the local (non-roaming)
+// app data directory on Windows and Application Support on macOS
+func stateDir() (string, error) {
+	var base string
+	var err error
+	switch {
+	case os.Getenv("XDG_STATE_HOME") != "":
+		base = os.Getenv("XDG_STATE_HOME")
+	case runtime.GOOS == "windows":
+		base, err = os.UserCacheDir()
+	case runtime.GOOS == "darwin":
+		base, err = os.UserConfigDir()
+	default:
+		var home string
+		home, err = os.UserHomeDir()
+		base = filepath.Join(home, ".local", "state")
+	}
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "claude-ride-with-whip", "sessions"), nil
+}
+
+// stateStore keeps one JSON file per session, guarded by a lock file
+type stateStore struct {
+	Dir    string
+	MaxAge time.Duration
+}
+
+// openStateStore returns the store in the user state directory
+func openStateStore() (*stateStore, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return &stateStore{Dir: dir, MaxAge: stateMaxAge}, nil
+}
+
+// update loads the state of a session, applies fn and writes it back,
+// all under the session lock so concurrent invocations never lose an
+// update. Unreadable or other-version state starts over. Collection runs
+// on the wall clock, since it compares file modification times
+func (s *stateStore) update(key string, fn func(st *sessionState)) (sessionState, error) {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return sessionState{}, err
+	}
+	lock, err := acquireLock(filepath.Join(s.Dir, key+".lock"), stateLockTimeout)
+	if err != nil {
+		return sessionState{}, err
+	}
+	defer releaseLock(lock)
+
+	st := s.load(key)
+	fn(&st)
+	data, err := json.Marshal(st)
+	if err != nil {
+		return st, err
+	}
+	if err := writeFileAtomic(filepath.Join(s.Dir, key+".json"), data); err != nil {
+		return st, err
+	}
+
+	s.collect(time.Now())
+	return st, nil
+}
+
+// load reads a session state, returning a fresh one when there is none
+func (s *stateStore) load(key string) sessionState {
+	fresh := sessionState{Version: stateSchemaVersion, Key: key}
+	data, err := os.ReadFile(filepath.Join(s.Dir, key+".json"))
+	if err != nil {
+		return fresh
+	}
+	var st sessionState
+	if json.Unmarshal(data, &st) != nil || st.Version != stateSchemaVersion || st.Key != key {
+		return fresh
+	}
+	return st
+}
+
+// collect removes sessions not updated within MaxAge, at most once per
+// stateGCInterval. Errors are ignored: a later call will try again
+func (s *stateStore) collect(now time.Time) {
+	marker := filepath.Join(s.Dir, stateGCMarker)
+	if info, err := os.Stat(marker); err == nil && now.Sub(info.ModTime()) < stateGCInterval {
+		return
+	}
+	if f, err := os.Create(marker); err == nil {
+		f.Close()
+		os.Chtimes(marker, now, now)
+	}
+
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return
+	}
+
+	// A lock file keeps its creation time while its session is active,
+	// so a session's files are judged by the age of its state file
+	active := map[string]bool{}
+	for _, e := range entries {
+		key, ok := strings.CutSuffix(e.Name(), ".json")
+		if info, err := e.Info(); ok && err == nil && now.Sub(info.ModTime()) < s.MaxAge {
+			active[key] = true
+		}
+	}
+	for _, e := range entries {
+		name := e.Name()
+		key, _, _ := strings.Cut(name, ".")
+		if e.IsDir() || key == "" || active[key] {
+			continue
+		}
+		if info, err := e.Info(); err == nil && now.Sub(info.ModTime()) >= s.MaxAge {
+			os.Remove(filepath.Join(s.Dir, name))
+		}
+	}
+}
+
+// acquireLock opens the lock file and takes an exclusive lock on it,
+// retrying until timeout
+func acquireLock(path string, timeout time.Duration) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, errLockBusy) || time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %w", filepath.Base(path), err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// releaseLock unlocks and closes a lock file from acquireLock
+func releaseLock(f *os.File) {
+	unlockFile(f)
+	f.Close()
+}
+
+// recordSession records a call in the stored state of the payload's
+// session and returns the updated state, or nil when the payload has no
+// session or the store is unavailable; the statusline then renders
+// without session data
+func recordSession(input *StatusLineInput, now time.Time, cfg Config, debugFile *os.File) *sessionState {
+	key := sessionKey(input)
+	if key == "" {
+		return nil
+	}
+	store, err := openStateStore()
+	if err != nil {
+		debugLog(debugFile, "state: %v", err)
+		return nil
+	}
+	st, err := store.update(key, func(st *sessionState) {
+		updateSessionState(st, input, now, cfg.Alerts.Rules)
+		updateTitle(st, cfg.Title, cfg.Path, input, now, debugFile)
+	})
+	if err != nil {
+		debugLog(debugFile, "state: %v", err)
+		return nil
+	}
+	return &st
+}
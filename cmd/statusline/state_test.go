@@ -0,0 +1,158 @@
+// Package main provides tests for the per-session state store
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionKey(t *testing.T) {
+	assert.Equal(t, "", sessionKey(nil))
+	assert.Equal(t, "", sessionKey(&StatusLineInput{}))
+	assert.Equal(t, "abc-123_X", sessionKey(&StatusLineInput{SessionID: "abc-123_X"}))
+
+	unsafe := sessionKey(&StatusLineInput{SessionID: "../../etc/passwd"})
+	assert.Len(t, unsafe, 32, "ids unsafe as file names are hashed")
+
+	byTranscript := sessionKey(&StatusLineInput{TranscriptPath: "/tmp/t.jsonl"})
+	assert.Equal(t, byTranscript, sessionKey(&StatusLineInput{TranscriptPath: "/tmp/t.jsonl"}))
+	assert.NotEqual(t, byTranscript, sessionKey(&StatusLineInput{TranscriptPath: "/tmp/u.jsonl"}))
+}
+
+func TestStateStore_Update(t *testing.T) {
+	store := &stateStore{Dir: t.TempDir(), MaxAge: stateMaxAge}
+	t0 := time.UnixMilli(1_700_000_000_000)
+	input := &StatusLineInput{SessionID: "s1"}
+
+	advance := func(now time.Time) sessionState {
+		st, err := store.update("s1", func(st *sessionState) { updateSessionState(st, input, now, nil) })
+		require.NoError(t, err)
+		return st
+	}
+	advance(t0)
+	st := advance(t0.Add(3 * time.Second))
+
+	assert.Equal(t, 2, st.Calls)
+	assert.Equal(t, 3*time.Second, st.idle())
+	assert.True(t, st.Created.Equal(t0))
+	loaded := store.load("s1")
+	assert.Equal(t, 2, loaded.Calls, "the update was persisted")
+	assert.True(t, loaded.LastCall.Equal(st.LastCall))
+}
+
+func TestStateStore_SchemaMismatchStartsOver(t *testing.T) {
+	store := &stateStore{Dir: t.TempDir(), MaxAge: stateMaxAge}
+	old := `{"version": 0, "key": "s1", "calls": 41}`
+	require.NoError(t, os.WriteFile(filepath.Join(store.Dir, "s1.json"), []byte(old), 0600))
+
+	st := store.load("s1")
+	assert.Equal(t, stateSchemaVersion, st.Version)
+	assert.Zero(t, st.Calls)
+}
+
+func TestStateStore_ConcurrentUpdates(t *testing.T) {
+	store := &stateStore{Dir: t.TempDir(), MaxAge: stateMaxAge}
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every update opens its own lock file handle, like separate processes
+			for {
+				_, err := store.update("s1", func(st *sessionState) { st.Calls++ })
+				if err == nil {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, n, store.load("s1").Calls, "no update is lost")
+}
+
+func TestAcquireLock_Busy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.lock")
+	held, err := acquireLock(path, time.Second)
+	require.NoError(t, err)
+
+	_, err = acquireLock(path, 20*time.Millisecond)
+	assert.ErrorIs(t, err, errLockBusy)
+
+	releaseLock(held)
+	again, err := acquireLock(path, 20*time.Millisecond)
+	require.NoError(t, err)
+	releaseLock(again)
+}
+
+func TestStateStore_Collect(t *testing.T) {
+	dir := t.TempDir()
+	store := &stateStore{Dir: dir, MaxAge: time.Hour}
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	touch := func(name string, at time.Time) {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, nil, 0600))
+		require.NoError(t, os.Chtimes(p, at, at))
+	}
+	touch("stale.json", old)
+	touch("stale.lock", old)
+	touch("active.json", now)
+	touch("active.lock", old) // Lock files keep their creation time
+
+	store.collect(now)
+	assert.NoFileExists(t, filepath.Join(dir, "stale.json"))
+	assert.NoFileExists(t, filepath.Join(dir, "stale.lock"))
+	assert.FileExists(t, filepath.Join(dir, "active.json"))
+	assert.FileExists(t, filepath.Join(dir, "active.lock"))
+
+	// Within the interval nothing is scanned
+	touch("later.json", old)
+	store.collect(now.Add(time.Minute))
+	assert.FileExists(t, filepath.Join(dir, "later.json"))
+}
+
+func TestRecordSession(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cfg := defaultConfig()
+
+	assert.Nil(t, recordSession(&StatusLineInput{}, time.Now(), cfg, nil), "no key, no session")
+
+	input := &StatusLineInput{SessionID: "abc"}
+	t0 := time.Now()
+	first := recordSession(input, t0, cfg, nil)
+	require.NotNil(t, first)
+	assert.Equal(t, "abc", first.Key)
+	assert.Equal(t, 1, first.Calls)
+
+	second := recordSession(input, t0.Add(3*time.Second), cfg, nil)
+	require.NotNil(t, second)
+	assert.Equal(t, 2, second.Calls, "the state is kept between calls")
+	assert.Equal(t, 3*time.Second, second.idle())
+}
+
+func TestRecordSession_CollectsOnWallClock(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir, err := stateDir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	other := filepath.Join(dir, "other.json")
+	require.NoError(t, os.WriteFile(other, nil, 0600))
+
+	future := time.Now().AddDate(5, 0, 0)
+	require.NotNil(t, recordSession(&StatusLineInput{SessionID: "abc"}, future, defaultConfig(), nil))
+
+	assert.FileExists(t, other, "a session clock in the future deletes nothing")
+	info, err := os.Stat(filepath.Join(dir, stateGCMarker))
+	require.NoError(t, err)
+	assert.False(t, info.ModTime().After(time.Now()), "the marker is never dated in the future")
+}
@@ -0,0 +1,147 @@
+// Package main provides the computed status model shared by output backends
+package main
+
+// statusModel is everything the plugin computes from one invocation
+// It is emitted as-is by --format json for tmux scripts, prompt themes
+// and editor plugins
+type statusModel struct {
+	Gait        string           `json:"gait"`
+	Frame       int              `json:"frame"`
+	Position    int              `json:"position"`
+	MaxPosition int              `json:"max_position"`
+	SessionID   string           `json:"session_id,omitempty"`
+	Version     string           `json:"version,omitempty"`      // Claude Code version
+	OutputStyle string           `json:"output_style,omitempty"` // Active output style
+	Model       modelInfo        `json:"model"`
+	Context     contextInfo      `json:"context"`
+	Cost        costInfo         `json:"cost"`
+	RateLimit   rateInfo         `json:"rate_limit"`
+	Git         *gitInfo         `json:"git,omitempty"`
+	Throughput  float64          `json:"throughput"`         // Output tokens per second, 0 when unmeasured
+	Speed       float64          `json:"speed"`              // Gallop speed factor, 1 is the classic pace
+	Forecast    *contextForecast `json:"forecast,omitempty"` // Context fill forecast, nil until measurable
+	Compactions int              `json:"compactions"`        // Compactions detected in the session
+	Alerts      []alertEvent     `json:"alerts"`
+}
+
+// modelInfo identifies the active model
+type modelInfo struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+// contextInfo describes context window usage
+type contextInfo struct {
+	UsedTokens int     `json:"used_tokens"`
+	WindowSize int     `json:"window_size"`
+	Ratio      float64 `json:"ratio"` // 0..1, 0 when the window size is unknown
+}
+
+// costInfo describes the session cost and activity totals
+type costInfo struct {
+	TotalUSD      float64 `json:"total_usd"`
+	DurationMS    int64   `json:"duration_ms"`
+	APIDurationMS int64   `json:"api_duration_ms"`
+	LinesAdded    int     `json:"lines_added"`
+	LinesRemoved  int     `json:"lines_removed"`
+}
+
+// rateInfo describes the remaining rate limit
+type rateInfo struct {
+	Remaining int     `json:"remaining"`
+	Limit     int     `json:"limit"`
+	Ratio     float64 `json:"ratio"` // Remaining share, 1 when the limit is unknown
+}
+
+// alertEvent is an active alert
+type alertEvent struct {
+	Name    string `json:"name"`
+	Level   string `json:"level"` // levelWarning or levelError
+	Message string `json:"message"`
+}
+
+// Alert thresholds
+const (
+	contextWarningRatio = 0.80
+	contextErrorRatio   = 0.95
+	rateLimitLowRatio   = 0.10
+)
+
+// contextUsage returns the tokens currently in the context window
+// Usage is the current request's input including cached tokens
+func contextUsage(input *StatusLineInput) contextInfo {
+	if input == nil {
+		return contextInfo{}
+	}
+	usage := input.ContextWindow.CurrentUsage
+	info := contextInfo{
+		UsedTokens: usage.InputTokens + usage.CacheReadInputTokens +
+			usage.CacheCreationTokens + usage.CacheCreationInputTokens,
+		WindowSize: input.ContextWindow.ContextWindowSize,
+	}
+	if info.WindowSize > 0 {
+		info.Ratio = float64(info.UsedTokens) / float64(info.WindowSize)
+	}
+	return info
+}
+
+// rateLimitUsage returns the remaining share of the rate limit
+func rateLimitUsage(input *StatusLineInput) rateInfo {
+	info := rateInfo{Ratio: 1}
+	if input == nil {
+		return info
+	}
+	info.Remaining = input.RateLimit.Remaining
+	info.Limit = input.RateLimit.Limit
+	if info.Limit > 0 {
+		info.Ratio = float64(info.Remaining) / float64(info.Limit)
+	}
+	return info
+}
+
+// workingDir returns the directory the session works in
+func workingDir(input *StatusLineInput) string {
+	if input == nil {
+		return ""
+	}
+	if input.Workspace.CurrentDir != "" {
+		return input.Workspace.CurrentDir
+	}
+	return input.Cwd
+}
+
+// buildStatusModel computes the status model for an input and horse state
+// Session figures stay zero without a session; alerts are evaluated with
+// the configured rules
+func buildStatusModel(input *StatusLineInput, state horseState, session *sessionState, rules []alertRule) statusModel {
+	model := statusModel{
+		Gait:        state.Gait,
+		Frame:       state.Frame,
+		Position:    state.Position,
+		MaxPosition: state.MaxPos,
+		Context:     contextUsage(input),
+		RateLimit:   rateLimitUsage(input),
+		Git:         findGitInfo(workingDir(input)),
+	}
+	if input != nil {
+		model.Model = modelInfo{ID: input.Model.ID, DisplayName: input.Model.DisplayName}
+		model.SessionID = input.SessionID
+		model.Version = input.Version
+		model.OutputStyle = input.OutputStyle.Name
+		model.Cost = costInfo{
+			TotalUSD:      input.Cost.TotalCostUSD,
+			DurationMS:    input.Cost.TotalDurationMS,
+			APIDurationMS: input.Cost.TotalAPIDurationMS,
+			LinesAdded:    input.Cost.TotalLinesAdded,
+			LinesRemoved:  input.Cost.TotalLinesRemoved,
+		}
+	}
+	model.Speed = session.speed()
+	if session != nil {
+		model.Throughput = session.Throughput
+		model.Compactions = session.Compactions
+	}
+	model.Alerts = evaluateAlerts(rules, newAlertMetrics(input, session.idle()))
+	model.Forecast = session.forecast()
+	return model
+}
@@ -0,0 +1,71 @@
+// Package main provides tests for the computed status model
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildStatusModel(t *testing.T) {
+	var input StatusLineInput
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"model": {"id": "claude-opus", "display_name": "Opus"},
+		"context_window": {
+			"context_window_size": 200000,
+			"current_usage": {"input_tokens": 1000, "cache_read_input_tokens": 189000}
+		},
+		"rate_limit": {"remaining": 5, "limit": 100},
+		"cost": {"total_cost_usd": 2.5}
+	}`), &input))
+
+	model := buildStatusModel(&input, horseState{Frame: 3, Position: 10, MaxPos: 75}, nil, defaultAlertRules())
+
+	assert.Equal(t, 3, model.Frame)
+	assert.Equal(t, 10, model.Position)
+	assert.Equal(t, "Opus", model.Model.DisplayName)
+	assert.Equal(t, 190000, model.Context.UsedTokens)
+	assert.InDelta(t, 0.95, model.Context.Ratio, 1e-9)
+	assert.Equal(t, 2.5, model.Cost.TotalUSD)
+	assert.InDelta(t, 0.05, model.RateLimit.Ratio, 1e-9)
+
+	require.Len(t, model.Alerts, 2)
+	assert.Equal(t, "context", model.Alerts[0].Name)
+	assert.Equal(t, "error", model.Alerts[0].Level)
+	assert.Equal(t, "rate_limit", model.Alerts[1].Name)
+}
+
+func TestBuildStatusModel_ConfiguredRulesAndSession(t *testing.T) {
+	input := &StatusLineInput{}
+	input.Cost.TotalCostUSD = 12
+	t0 := time.UnixMilli(1_700_000_000_000)
+	session := &sessionState{PrevCall: t0, LastCall: t0.Add(10 * time.Minute), Compactions: 2}
+	rules := []alertRule{
+		{Name: "budget", Metric: metricCost, Threshold: 10, Level: levelError},
+		{Name: "away", Metric: metricIdle, Threshold: 300, Level: levelWarning},
+	}
+
+	model := buildStatusModel(input, horseState{}, session, rules)
+
+	require.Len(t, model.Alerts, 2)
+	assert.Equal(t, "budget", model.Alerts[0].Name)
+	assert.Equal(t, "away", model.Alerts[1].Name, "idle comes from the session")
+	assert.Equal(t, 2, model.Compactions)
+	assert.Empty(t, buildStatusModel(input, horseState{}, nil, nil).Alerts, "no built-in rules sneak in")
+}
+
+func TestBuildStatusModel_NilInput(t *testing.T) {
+	model := buildStatusModel(nil, horseState{}, nil, defaultAlertRules())
+
+	assert.Zero(t, model.Context.Ratio)
+	assert.Equal(t, 1.0, model.RateLimit.Ratio, "unknown limit is not low")
+	assert.Nil(t, model.Git)
+
+	// Alerts encode as an empty list, never null
+	data, err := json.Marshal(model)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"alerts":[]`)
+}
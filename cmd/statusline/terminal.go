@@ -0,0 +1,15 @@
+// Package main provides platform independent terminal helpers
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+// envTerminalSize reads the terminal size from the COLUMNS and LINES
+// environment variables, which shells export for child processes
+func envTerminalSize() (cols, rows int, ok bool) {
+	cols, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	rows, _ = strconv.Atoi(os.Getenv("LINES"))
+	return cols, rows, cols > 0
+}
@@ -0,0 +1,139 @@
+// Package main provides the color theme and styled text runs
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// colorDefault marks a run that uses the terminal's default foreground color
+const colorDefault = -1
+
+// Theme holds the xterm 256-color palette indexes used for rendering
+type Theme struct {
+	Sprite  int // Horse sprite color
+	Track   int // Dotted track color, colorDefault for the terminal default
+	Segment int // Status segment text color
+}
+
+// defaultTheme is the China red horse on a default colored track
+var defaultTheme = Theme{Sprite: 160, Track: colorDefault, Segment: colorDefault}
+
+// currentTheme returns the theme used by every output backend
+func currentTheme() Theme {
+	return defaultTheme
+}
+
+// styledRun is a piece of text drawn in a single color
+type styledRun struct {
+	Text  string
+	Color int    // xterm 256-color index or colorDefault
+	Link  string // OSC 8 hyperlink target, empty for plain text
+}
+
+// styledLine is one output row made of colored runs
+type styledLine []styledRun
+
+// plain returns the text of the line without colors
+func (l styledLine) plain() string {
+	var b strings.Builder
+	for _, run := range l {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// width returns the terminal cell width of the line
+func (l styledLine) width() int {
+	return StringWidth(l.plain())
+}
+
+// styleLines styles every rendered horse line
+func styleLines(lines []string, theme Theme) []styledLine {
+	styled := make([]styledLine, len(lines))
+	for i, line := range lines {
+		styled[i] = styleLine(line, theme)
+	}
+	return styled
+}
+
+// styleLine splits a rendered horse line into colored runs
+// Dots and spaces use the track color, other characters the sprite color
+func styleLine(line string, theme Theme) styledLine {
+	var runs styledLine
+	var current strings.Builder
+	currentColor := colorDefault
+	started := false
+
+	for _, ch := range line {
+		c := theme.Sprite
+		if ch == '.' || ch == ' ' {
+			c = theme.Track
+		}
+		if started && c != currentColor {
+			runs = append(runs, styledRun{Text: current.String(), Color: currentColor})
+			current.Reset()
+		}
+		currentColor = c
+		started = true
+		current.WriteRune(ch)
+	}
+	if current.Len() > 0 {
+		runs = append(runs, styledRun{Text: current.String(), Color: currentColor})
+	}
+	return runs
+}
+
+// ansiLine renders styled runs with ANSI color escape sequences
+func ansiLine(line styledLine) string {
+	var result strings.Builder
+
+	for _, run := range line {
+		text := run.Text
+		if run.Color != colorDefault {
+			text = ansiColor(run.Color) + text + colorReset
+		}
+		if run.Link != "" {
+			text = hyperlink(run.Link, text)
+		}
+		result.WriteString(text)
+	}
+
+	return result.String()
+}
+
+// ansiColor returns the SGR sequence selecting an xterm 256 color
+func ansiColor(c int) string {
+	return fmt.Sprintf("\x1b[38;5;%dm", c)
+}
+
+// xterm256RGB converts an xterm 256-color palette index to RGB
+// colorDefault maps to a light gray, the usual default foreground
+func xterm256RGB(c int) color.RGBA {
+	switch {
+	case c < 0:
+		return color.RGBA{0xc0, 0xc0, 0xc0, 0xff}
+	case c < 16:
+		// Standard and bright system colors (xterm defaults)
+		system := [16][3]uint8{
+			{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+			{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+			{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+			{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+		}
+		rgb := system[c]
+		return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}
+	case c < 232:
+		// 6x6x6 color cube
+		levels := [6]uint8{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+		c -= 16
+		return color.RGBA{levels[c/36], levels[(c/6)%6], levels[c%6], 0xff}
+	case c < 256:
+		// Grayscale ramp
+		v := uint8(8 + (c-232)*10)
+		return color.RGBA{v, v, v, 0xff}
+	default:
+		return xterm256RGB(colorDefault)
+	}
+}
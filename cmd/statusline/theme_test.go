@@ -0,0 +1,41 @@
+// Package main provides tests for themes and styled runs
+package main
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStyleLine(t *testing.T) {
+	runs := styleLine("..🐴⏜)..", defaultTheme)
+
+	assert.Equal(t, styledLine{
+		{Text: "..", Color: colorDefault},
+		{Text: "🐴⏜)", Color: 160},
+		{Text: "..", Color: colorDefault},
+	}, runs)
+}
+
+func TestColorizeLine_MatchesStyledRuns(t *testing.T) {
+	assert.Equal(t, ".\x1b[38;5;160m🐴\x1b[0m.", colorizeLine(".🐴."))
+	assert.Equal(t, "....", colorizeLine("...."), "dots need no escape codes")
+}
+
+func TestXterm256RGB(t *testing.T) {
+	tests := []struct {
+		index    int
+		expected color.RGBA
+	}{
+		{index: 1, expected: color.RGBA{0xcd, 0x00, 0x00, 0xff}},
+		{index: 16, expected: color.RGBA{0x00, 0x00, 0x00, 0xff}},
+		{index: 160, expected: color.RGBA{0xd7, 0x00, 0x00, 0xff}},
+		{index: 231, expected: color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{index: 232, expected: color.RGBA{0x08, 0x08, 0x08, 0xff}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, xterm256RGB(tt.index), "color %d", tt.index)
+	}
+}
@@ -0,0 +1,98 @@
+// Package main provides the output token throughput meter, which also
+// sets how fast the horse gallops
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Throughput smoothing and the mapping onto gallop speed
+const (
+	throughputSmoothing = 0.5  // Weight of the newest sample in the moving average
+	tokensPerSpeed      = 40.0 // Output tok/s worth one extra 1x of speed
+	minGallopSpeed      = 0.25 // A stalled model walks
+	maxGallopSpeed      = 2.5  // A fast-streaming model gallops flat out
+)
+
+// sampleThroughput measures output tokens per second since the previous
+// call from the TotalOutputTokens delta, smoothed across calls
+func sampleThroughput(st *sessionState, input *StatusLineInput, now time.Time) {
+	if input == nil {
+		return
+	}
+	total := input.ContextWindow.TotalOutputTokens
+	prev, prevAt := st.OutputTokens, st.LastCall
+	st.OutputTokens = total
+	if total != prev || st.OutputAt.IsZero() {
+		st.OutputAt = now
+	}
+
+	elapsed := now.Sub(prevAt).Seconds()
+	if prevAt.IsZero() || elapsed <= 0 || total < prev {
+		// No baseline yet, or the counter was reset: start measuring again
+		return
+	}
+	rate := float64(total-prev) / elapsed
+	if st.ThroughputSamples == 0 {
+		st.Throughput = rate
+	} else {
+		st.Throughput = throughputSmoothing*rate + (1-throughputSmoothing)*st.Throughput
+	}
+	st.ThroughputSamples++
+}
+
+// gallopSpeed maps output throughput onto an animation speed factor:
+// 1x (250ms frames, 500ms steps) at 30 tok/s, slower below, faster above
+func gallopSpeed(tokensPerSecond float64) float64 {
+	return min(max(minGallopSpeed+tokensPerSecond/tokensPerSpeed, minGallopSpeed), maxGallopSpeed)
+}
+
+// speed returns the current gallop speed of the session
+// Without a measurement the horse keeps the classic 1x pace
+func (s *sessionState) speed() float64 {
+	if s == nil || s.ThroughputSamples == 0 {
+		return 1
+	}
+	return gallopSpeed(s.Throughput)
+}
+
+// advanceAnimation moves the session's animation clock by the time since
+// the previous call, scaled by the gallop speed. Driving the horse from
+// this clock rather than wall time keeps it from jumping when the speed
+// changes between calls
+func advanceAnimation(st *sessionState, now time.Time) {
+	if st.LastCall.IsZero() || st.AnimationMS == 0 {
+		st.AnimationMS = now.UnixMilli()
+		return
+	}
+	if elapsed := now.Sub(st.LastCall); elapsed > 0 {
+		st.AnimationMS += int64(float64(elapsed.Milliseconds()) * st.speed())
+	}
+}
+
+// animationTime returns the animation clock at now, extrapolated from the
+// last call so live views keep moving between payloads
+func (s *sessionState) animationTime(now time.Time) time.Time {
+	if s == nil || s.AnimationMS == 0 {
+		return now
+	}
+	ms := s.AnimationMS
+	if elapsed := now.Sub(s.LastCall); elapsed > 0 {
+		ms += int64(float64(elapsed.Milliseconds()) * s.speed())
+	}
+	return time.UnixMilli(ms)
+}
+
+// throughputSegment shows the output token rate of the session
+func throughputSegment(ctx *segmentContext) segment {
+	s := ctx.Session
+	if s == nil || s.ThroughputSamples == 0 {
+		return segment{Color: colorDefault}
+	}
+	format := "⚡%.0f tok/s"
+	if s.Throughput < 10 {
+		format = "⚡%.1f tok/s"
+	}
+	return segment{Text: fmt.Sprintf(format, s.Throughput), Color: colorDefault}
+}
@@ -0,0 +1,88 @@
+// Package main provides tests for the output token throughput meter
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPayload holds the payload fields the session tests vary, so each
+// case spells out only what it sets
+type testPayload struct {
+	Output  int     // Output token total
+	Input   int     // Input token total
+	Used    int     // Context tokens used
+	Window  int     // Context window size
+	CostUSD float64 // Session cost
+	Project string  // Project directory
+	Dir     string  // Current directory
+	Model   string  // Model display name
+}
+
+// build returns the payload with the fields of p
+func (p testPayload) build() *StatusLineInput {
+	input := &StatusLineInput{}
+	input.ContextWindow.TotalOutputTokens = p.Output
+	input.ContextWindow.TotalInputTokens = p.Input
+	input.ContextWindow.CurrentUsage.InputTokens = p.Used
+	input.ContextWindow.ContextWindowSize = p.Window
+	input.Cost.TotalCostUSD = p.CostUSD
+	input.Workspace.ProjectDir = p.Project
+	input.Workspace.CurrentDir = p.Dir
+	input.Model.DisplayName = p.Model
+	return input
+}
+
+func TestSampleThroughput(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+
+	updateSessionState(&st, testPayload{Output: 1000}.build(), t0, nil)
+	assert.Zero(t, st.ThroughputSamples, "the first call only sets the baseline")
+
+	updateSessionState(&st, testPayload{Output: 1100}.build(), t0.Add(2*time.Second), nil)
+	assert.InDelta(t, 50, st.Throughput, 0.001)
+
+	updateSessionState(&st, testPayload{Output: 1100}.build(), t0.Add(4*time.Second), nil)
+	assert.InDelta(t, 25, st.Throughput, 0.001, "a stall halves the smoothed rate")
+
+	updateSessionState(&st, testPayload{Output: 10}.build(), t0.Add(6*time.Second), nil)
+	assert.InDelta(t, 25, st.Throughput, 0.001, "a counter reset takes no sample")
+	assert.Equal(t, 10, st.OutputTokens)
+}
+
+func TestGallopSpeed(t *testing.T) {
+	assert.Equal(t, minGallopSpeed, gallopSpeed(0))
+	assert.InDelta(t, 1, gallopSpeed(30), 0.001)
+	assert.Equal(t, maxGallopSpeed, gallopSpeed(10_000))
+
+	var none *sessionState
+	assert.Equal(t, 1.0, none.speed(), "stateless renders keep the classic pace")
+}
+
+func TestAnimationTime_ScalesWithSpeed(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	var st sessionState
+	updateSessionState(&st, testPayload{Output: 0}.build(), t0, nil)
+	assert.Equal(t, t0, st.animationTime(t0))
+
+	// 90 tok/s gallops at 2.5x: one wall second moves the clock 2.5s
+	updateSessionState(&st, testPayload{Output: 90}.build(), t0.Add(time.Second), nil)
+	assert.Equal(t, t0.Add(2500*time.Millisecond), st.animationTime(t0.Add(time.Second)))
+	assert.Equal(t, t0.Add(5000*time.Millisecond), st.animationTime(t0.Add(2*time.Second)), "extrapolated between calls")
+
+	var none *sessionState
+	assert.Equal(t, t0, none.animationTime(t0))
+}
+
+func TestThroughputSegment(t *testing.T) {
+	assert.Empty(t, throughputSegment(&segmentContext{}).Text)
+	assert.Empty(t, throughputSegment(&segmentContext{Session: &sessionState{}}).Text)
+
+	ctx := &segmentContext{Session: &sessionState{Throughput: 42.4, ThroughputSamples: 1}}
+	assert.Equal(t, "⚡42 tok/s", throughputSegment(ctx).Text)
+	ctx.Session.Throughput = 3.25
+	assert.Equal(t, "⚡3.2 tok/s", throughputSegment(ctx).Text)
+}
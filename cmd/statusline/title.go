@@ -0,0 +1,122 @@
+// Package main provides the terminal window title: a templated summary of
+// the session sent with OSC 0 or 2 whenever it changes
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// titleConfig controls the window title sequence
+type titleConfig struct {
+	Enabled          bool   `json:"enabled"`
+	Template         string `json:"template"`           // Go text/template over titleData
+	OSC              int    `json:"osc"`                // 0 sets the icon name and window title, 2 the window title only
+	IdleAfterSeconds int    `json:"idle_after_seconds"` // No output this long marks the session idle
+}
+
+// defaultTitleConfig returns the title defaults
+func defaultTitleConfig() titleConfig {
+	return titleConfig{
+		Template:         `{{.Project}} · {{.Model}} · {{.Context}}%{{if .Idle}} 💤{{end}}`,
+		OSC:              0,
+		IdleAfterSeconds: int(gaitRestAfter / time.Second),
+	}
+}
+
+// validate checks the template and the OSC number
+func (c titleConfig) validate() error {
+	if c.OSC != 0 && c.OSC != 2 {
+		return fmt.Errorf("invalid title osc %d: want 0 or 2", c.OSC)
+	}
+	if c.IdleAfterSeconds < 0 {
+		return fmt.Errorf("title idle_after_seconds must not be negative")
+	}
+	if _, err := parseTitleTemplate(c.Template); err != nil {
+		return fmt.Errorf("title template: %w", err)
+	}
+	return nil
+}
+
+// titleData is the data passed to the title template
+type titleData struct {
+	Project string           // Base name of the project directory, redacted
+	Model   string           // Model display name
+	Context int              // Context window percent used
+	Idle    bool             // Nothing was streamed for idle_after_seconds
+	Input   *StatusLineInput // Decoded payload, with paths redacted
+}
+
+// parseTitleTemplate parses a title template
+func parseTitleTemplate(text string) (*template.Template, error) {
+	return template.New("title").Option("missingkey=zero").Parse(text)
+}
+
+// newTitleData returns the title data of a payload for a session at now
+// Paths go through the path segment's redaction: a window title is as
+// visible on a shared screen as the statusline
+func newTitleData(cfg titleConfig, paths pathConfig, input *StatusLineInput, st *sessionState, now time.Time) titleData {
+	data := titleData{Context: int(math.Round(contextUsage(input).Ratio * 100))}
+	if input != nil {
+		project := input.Workspace.ProjectDir
+		if project == "" {
+			project = workingDir(input)
+		}
+		if project != "" {
+			data.Project = paths.redactedName(project)
+		}
+		data.Model = input.Model.DisplayName
+
+		redacted := *input
+		redacted.Cwd = paths.redactedPath(input.Cwd)
+		redacted.Workspace.CurrentDir = paths.redactedPath(input.Workspace.CurrentDir)
+		redacted.Workspace.ProjectDir = paths.redactedPath(input.Workspace.ProjectDir)
+		redacted.TranscriptPath = paths.redactedPath(input.TranscriptPath)
+		data.Input = &redacted
+	}
+	if st != nil && !st.OutputAt.IsZero() {
+		data.Idle = now.Sub(st.OutputAt) >= time.Duration(cfg.IdleAfterSeconds)*time.Second
+	}
+	return data
+}
+
+// windowTitle executes the title template
+func windowTitle(cfg titleConfig, data titleData) (string, error) {
+	tpl, err := parseTitleTemplate(cfg.Template)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return sanitizeText(strings.TrimSpace(b.String())), nil
+}
+
+// updateTitle records the window title of a call in the session state
+// TitleChanged tells the caller to send it; an unchanged title is never
+// sent again, however often the statusline renders
+func updateTitle(st *sessionState, cfg titleConfig, paths pathConfig, input *StatusLineInput, now time.Time, debugFile *os.File) {
+	st.TitleChanged = false
+	if !cfg.Enabled {
+		return
+	}
+	title, err := windowTitle(cfg, newTitleData(cfg, paths, input, st, now))
+	if err != nil {
+		debugLog(debugFile, "title template: %v", err)
+		return
+	}
+	if title != st.Title {
+		st.Title = title
+		st.TitleChanged = true
+	}
+}
+
+// titleSequence returns the OSC sequence that sets a window title
+func titleSequence(cfg titleConfig, title string) string {
+	return fmt.Sprintf("\x1b]%d;%s\a", cfg.OSC, title)
+}
@@ -0,0 +1,103 @@
+// Package main provides tests for the terminal window title
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// titleSession is a session in a project with 42% of the context used
+var titleSession = testPayload{
+	Used: 42, Window: 100, Model: "Opus",
+	Project: "/home/dev/horse-racing", Dir: "/home/dev/horse-racing/src",
+}
+
+func TestWindowTitle_Default(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	cfg := defaultTitleConfig()
+
+	title, err := windowTitle(cfg, newTitleData(cfg, defaultPathConfig(), titleSession.build(), nil, t0))
+	assert.NoError(t, err)
+	assert.Equal(t, "horse-racing · Opus · 42%", title)
+
+	st := &sessionState{OutputAt: t0.Add(-time.Minute)}
+	title, _ = windowTitle(cfg, newTitleData(cfg, defaultPathConfig(), titleSession.build(), st, t0))
+	assert.Equal(t, "horse-racing · Opus · 42% 💤", title)
+}
+
+func TestWindowTitle_Sanitized(t *testing.T) {
+	cfg := defaultTitleConfig()
+	input := titleSession.build()
+	input.Model.DisplayName = "Opus\x1b]0;pwned\a"
+
+	title, err := windowTitle(cfg, newTitleData(cfg, defaultPathConfig(), input, nil, time.Now()))
+	assert.NoError(t, err)
+	assert.NotContains(t, title, "\x1b")
+	assert.NotContains(t, title, "\a")
+}
+
+func TestWindowTitle_Redacted(t *testing.T) {
+	cfg := defaultTitleConfig()
+	cfg.Template = `{{.Project}} {{.Input.Workspace.CurrentDir}}`
+	paths := defaultPathConfig()
+	paths.Redact = []string{`^/home/dev/horse-[^/]+`}
+
+	title, err := windowTitle(cfg, newTitleData(cfg, paths, titleSession.build(), nil, time.Now()))
+	assert.NoError(t, err)
+	assert.Equal(t, "*** ***/src", title, "the project name and payload paths are redacted")
+
+	paths.Redact = []string{`^/home/dev`}
+	title, _ = windowTitle(cfg, newTitleData(cfg, paths, titleSession.build(), nil, time.Now()))
+	assert.Equal(t, "horse-racing ***/horse-racing/src", title, "a match on the parents keeps the name")
+
+	input := titleSession.build()
+	input.Workspace.ProjectDir = `C:\Users\dev\clients\acme`
+	input.Workspace.CurrentDir = `C:\Users\dev\clients\acme\src`
+	paths.Redact = []string{`/clients/[^/]+`}
+	title, _ = windowTitle(cfg, newTitleData(cfg, paths, input, nil, time.Now()))
+	assert.Equal(t, `*** C:\Users\dev***\src`, title, "windows separators are kept")
+}
+
+func TestUpdateTitle_OnlyOnChange(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	cfg := defaultTitleConfig()
+	var st sessionState
+
+	updateTitle(&st, cfg, defaultPathConfig(), titleSession.build(), t0, nil)
+	assert.False(t, st.TitleChanged, "disabled by default")
+	assert.Empty(t, st.Title)
+
+	cfg.Enabled = true
+	updateTitle(&st, cfg, defaultPathConfig(), titleSession.build(), t0, nil)
+	assert.True(t, st.TitleChanged)
+	assert.Equal(t, "horse-racing · Opus · 42%", st.Title)
+
+	updateTitle(&st, cfg, defaultPathConfig(), titleSession.build(), t0.Add(time.Second), nil)
+	assert.False(t, st.TitleChanged, "an unchanged title is not sent again")
+
+	busier := titleSession
+	busier.Used = 43
+	updateTitle(&st, cfg, defaultPathConfig(), busier.build(), t0.Add(2*time.Second), nil)
+	assert.True(t, st.TitleChanged)
+}
+
+func TestTitleSequence(t *testing.T) {
+	cfg := defaultTitleConfig()
+	assert.Equal(t, "\x1b]0;horse\a", titleSequence(cfg, "horse"))
+	cfg.OSC = 2
+	assert.Equal(t, "\x1b]2;horse\a", titleSequence(cfg, "horse"))
+}
+
+func TestTitleConfig_Validate(t *testing.T) {
+	assert.NoError(t, defaultTitleConfig().validate())
+
+	cfg := defaultTitleConfig()
+	cfg.OSC = 1
+	assert.Error(t, cfg.validate())
+
+	cfg = defaultTitleConfig()
+	cfg.Template = "{{.Project"
+	assert.Error(t, cfg.validate())
+}
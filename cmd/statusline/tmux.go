@@ -0,0 +1,102 @@
+// Package main provides the tmux status-line output backend
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultTmuxRow is the horse row shown in tmux: the one with the head
+const defaultTmuxRow = 1
+
+// lastInputFile is the cache file holding the most recent stdin payload
+const lastInputFile = "last_input.json"
+
+// renderTmux collapses the frame into the selected horse row followed by
+// the segments, and translates theme colors into tmux #[fg=colourN] markup
+func renderTmux(frame statusFrame, row int) string {
+	if len(frame.Horse) == 0 {
+		return ""
+	}
+	if row < 0 || row >= len(frame.Horse) {
+		row = min(defaultTmuxRow, len(frame.Horse)-1)
+	}
+
+	line := append(styledLine{}, frame.Horse[row]...)
+	if len(frame.Segments) > 0 {
+		line = append(line, styledRun{Text: " ", Color: colorDefault})
+		line = append(line, frame.Segments...)
+	}
+
+	var b strings.Builder
+	current := colorDefault - 1 // Forces a style tag before the first run
+	for _, run := range line {
+		if run.Color != current {
+			if run.Color == colorDefault {
+				b.WriteString("#[default]")
+			} else {
+				fmt.Fprintf(&b, "#[fg=colour%d]", run.Color)
+			}
+			current = run.Color
+		}
+		// "#" starts tmux formats, so literal ones must be doubled
+		b.WriteString(strings.ReplaceAll(run.Text, "#", "##"))
+	}
+	if current != colorDefault {
+		b.WriteString("#[default]")
+	}
+	return b.String()
+}
+
+// cacheDir returns the per-user cache directory of the plugin
+func cacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "claude-ride-with-whip"), nil
+}
+
+// cacheLastInput stores the raw stdin payload for "statusline tmux"
+// The file is replaced atomically so readers never see a partial payload
+func cacheLastInput(data []byte) error {
+	dir, err := cacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(dir, lastInputFile), data)
+}
+
+// writeFileAtomic replaces path with data through a temporary file in
+// the same directory, so readers never see a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadLastInput returns the cached stdin payload, if any
+func loadLastInput() ([]byte, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(dir, lastInputFile))
+}
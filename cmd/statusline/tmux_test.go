@@ -0,0 +1,57 @@
+// Package main provides tests for the tmux output backend
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTmux_ColorsAndRow(t *testing.T) {
+	frame := statusFrame{Horse: styleLines([]string{"....", "..🐴⏜..", "..ﾉﾉ..", "...."}, defaultTheme)}
+
+	assert.Equal(t, "#[default]..#[fg=colour160]🐴⏜#[default]..", renderTmux(frame, 1))
+	assert.Equal(t, "#[default]..#[fg=colour160]ﾉﾉ#[default]..", renderTmux(frame, 2))
+	assert.Equal(t, "#[default]..#[fg=colour160]🐴⏜#[default]..", renderTmux(frame, 9),
+		"out of range rows fall back to the head row")
+}
+
+func TestRenderTmux_Segments(t *testing.T) {
+	frame := statusFrame{
+		Horse:    styleLines([]string{"..🐴ﾉﾉ~.."}, defaultTheme),
+		Segments: segmentLine([]segment{{Text: "Opus", Color: colorDefault}, {Text: "ctx 96%", Color: 196}}, defaultTheme),
+		Compact:  true,
+	}
+
+	assert.Equal(t,
+		"#[default]..#[fg=colour160]🐴ﾉﾉ~#[default].. Opus · #[fg=colour196]ctx 96%#[default]",
+		renderTmux(frame, defaultTmuxRow),
+		"single-row frames use their only row")
+}
+
+func TestRenderTmux_SingleLine(t *testing.T) {
+	frame := statusFrame{Horse: styleLines(getHorseLines(nil, time.UnixMilli(0)), defaultTheme)}
+	out := renderTmux(frame, defaultTmuxRow)
+	assert.NotContains(t, out, "\n")
+	assert.NotContains(t, out, "\x1b", "tmux markup must not contain ANSI escapes")
+	assert.Contains(t, out, "🐴")
+}
+
+func TestRenderTmux_EscapesHash(t *testing.T) {
+	out := renderTmux(statusFrame{Horse: []styledLine{styleLine("#1", defaultTheme)}}, 0)
+	assert.Equal(t, "#[fg=colour160]##1#[default]", out)
+}
+
+func TestCacheLastInput_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LocalAppData", dir)
+
+	require.NoError(t, cacheLastInput([]byte(`{"cwd":"/tmp"}`)))
+	data, err := loadLastInput()
+	require.NoError(t, err)
+	assert.Equal(t, `{"cwd":"/tmp"}`, string(data))
+}
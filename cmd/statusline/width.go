@@ -1,7 +1,11 @@
 // Package main provides terminal width calculation utilities
 package main
 
-import "github.com/mattn/go-runewidth"
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
 
 // StringWidth returns the terminal display width of a string
 // It correctly handles emoji (2 cells), CJK characters (2 cells),
@@ -10,3 +14,22 @@ import "github.com/mattn/go-runewidth"
 func StringWidth(s string) int {
 	return runewidth.StringWidth(s)
 }
+
+// TruncateWidth cuts a string so it occupies at most width terminal cells
+// Wide characters that would straddle the limit are dropped entirely
+func TruncateWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return runewidth.Truncate(s, width, "")
+}
+
+// PadCenter prefixes a string with spaces so it is centered within width cells
+// Strings that are already wider than width are returned unchanged
+func PadCenter(s string, width int) string {
+	pad := (width - StringWidth(s)) / 2
+	if pad <= 0 {
+		return s
+	}
+	return strings.Repeat(" ", pad) + s
+}
@@ -160,3 +160,28 @@ func TestStringWidth_AllHorseSprites(t *testing.T) {
 		})
 	}
 }
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		expected string
+	}{
+		{name: "fits", input: "abc", width: 5, expected: "abc"},
+		{name: "cut ASCII", input: "abcdef", width: 3, expected: "abc"},
+		{name: "emoji straddling limit is dropped", input: "a🐴b", width: 2, expected: "a"},
+		{name: "zero width", input: "abc", width: 0, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TruncateWidth(tt.input, tt.width))
+		})
+	}
+}
+
+func TestPadCenter(t *testing.T) {
+	assert.Equal(t, "  🐴", PadCenter("🐴", 6))
+	assert.Equal(t, "abcdef", PadCenter("abcdef", 4))
+}